@@ -0,0 +1,149 @@
+package engines
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/gocolly/colly"
+)
+
+// ArxivExtra holds the details arXiv reports about a paper
+type ArxivExtra struct {
+	Authors         []string
+	PrimaryCategory string
+	Published       time.Time
+	Updated         time.Time
+	PDF             string `json:",omitempty" xml:",omitempty"`
+}
+
+const arxivPageSize = 10
+
+// Arxiv searches arXiv using its Atom query API
+func Arxiv() SearchEngine {
+	return ArxivAt("http://export.arxiv.org/api/query")
+}
+
+// ArxivAt searches the arXiv query API at api, e.g. a mirror or a local
+// server serving recorded feeds
+func ArxivAt(api string) SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl(api, path, nil)
+	}
+	caps := Capabilities{
+		DateRange:  true,
+		Sort:       true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "arxiv",
+			Short:        "A",
+			DisplayName:  "arXiv",
+			Homepage:     "https://arxiv.org",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			search := arxivQuery(query)
+			if options.From != nil || options.To != nil {
+				from, to := "000001010000", time.Now().Format("200601021504")
+				if options.From != nil {
+					from = options.From.Format("200601021504")
+				}
+				// the end day is included
+				if options.To != nil {
+					to = options.To.Format("20060102") + "2359"
+				}
+				search = "(" + search + ") AND submittedDate:[" + from + " TO " + to + "]"
+			}
+			params := url.Values{}
+			params.Set("search_query", search)
+			params.Set("start", "0")
+			params.Set("max_results", strconv.Itoa(arxivPageSize))
+			if options.Sort == SortDate {
+				params.Set("sortBy", "submittedDate")
+				params.Set("sortOrder", "descending")
+			}
+			return Url("?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			doc, err := xmlquery.Parse(bytes.NewReader(r.Body))
+			if err != nil {
+				return nil, "", err
+			}
+
+			var results []Result
+			for _, entry := range xmlquery.Find(doc, "//entry") {
+				id := childText(entry, "id")
+				if strings.Contains(id, "/api/errors") {
+					return nil, "", &APIError{URL: r.Request.URL.String(), Code: id[strings.LastIndex(id, "#")+1:], Message: childText(entry, "summary")}
+				}
+				var extra ArxivExtra
+				for _, link := range xmlquery.Find(entry, "link") {
+					if link.SelectAttr("title") == "pdf" {
+						extra.PDF = link.SelectAttr("href")
+					}
+				}
+				for _, author := range xmlquery.Find(entry, "author/name") {
+					extra.Authors = append(extra.Authors, strings.TrimSpace(author.InnerText()))
+				}
+				if category := localChild(entry, "primary_category"); category != nil {
+					extra.PrimaryCategory = category.SelectAttr("term")
+				}
+				if published := feedDate(childText(entry, "published")); published != nil {
+					extra.Published = *published
+				}
+				if updated := feedDate(childText(entry, "updated")); updated != nil {
+					extra.Updated = *updated
+				}
+				link := atomLink(entry, "alternate")
+				if link == "" {
+					link = id
+				}
+				results = append(results, Result{
+					Title:       strings.Join(strings.Fields(childText(entry, "title")), " "),
+					Link:        link,
+					Description: strings.Join(strings.Fields(childText(entry, "summary")), " "),
+					Date:        feedDate(childText(entry, "published")),
+					Extra:       extra,
+				})
+			}
+
+			var total int
+			if feed := xmlquery.FindOne(doc, "//feed"); feed != nil {
+				if totalResults := localChild(feed, "totalResults"); totalResults != nil {
+					total, _ = strconv.Atoi(strings.TrimSpace(totalResults.InnerText()))
+				}
+			}
+			if len(results) == 0 || page*arxivPageSize >= total {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("start", strconv.Itoa(page*arxivPageSize))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
+
+// arxivQuery searches all fields for every word of query, unless it already
+// uses the field prefixes of the arXiv query syntax like ti: or au:
+func arxivQuery(query string) string {
+	if strings.Contains(query, ":") {
+		return query
+	}
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, "all:"+word)
+	}
+	return strings.Join(terms, " AND ")
+}
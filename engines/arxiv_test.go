@@ -0,0 +1,142 @@
+package engines
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArxiv(t *testing.T) {
+	srv := newFixtureServer(t, "application/atom+xml; charset=utf-8", "arxiv.xml", "arxiv_2.xml")
+	en := ArxivAt(srv.URL + "/api/query")
+
+	results, err := en.Crawl("electron", &SearchOptions{Pages: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "Multi-Electron Production at High Transverse Momenta in ep Collisions at HERA",
+			Link:        "http://arxiv.org/abs/hep-ex/0307015v1",
+			Description: "Multi-electron production is studied at high electron transverse momentum in positron- and electron-proton collisions using the H1 detector at HERA.",
+		},
+		{
+			Title:       "Impact of Electron-Electron Cusp on Configuration Interaction Energies",
+			Link:        "http://arxiv.org/abs/cond-mat/0102536v1",
+			Description: "The effect of the electron-electron cusp on the convergence of configuration interaction (CI) wave functions is examined.",
+		},
+		{
+			Title:       "Electron Transport in Graphene Nanoribbons",
+			Link:        "http://arxiv.org/abs/1102.0001v2",
+			Description: "We study electron transport in graphene nanoribbons.",
+		},
+	})
+	extras := []ArxivExtra{
+		{
+			Authors:         []string{"H1 Collaboration"},
+			PrimaryCategory: "hep-ex",
+			Published:       time.Date(2003, 7, 7, 17, 46, 39, 0, time.UTC),
+			Updated:         time.Date(2003, 7, 7, 17, 46, 39, 0, time.UTC),
+			PDF:             "http://arxiv.org/pdf/hep-ex/0307015v1",
+		},
+		{
+			Authors:         []string{"David Prendergast", "M. Nolan", "Claudia Filippi"},
+			PrimaryCategory: "cond-mat.str-el",
+			Published:       time.Date(2001, 2, 28, 20, 12, 9, 0, time.UTC),
+			Updated:         time.Date(2001, 2, 28, 20, 12, 9, 0, time.UTC),
+			PDF:             "http://arxiv.org/pdf/cond-mat/0102536v1",
+		},
+		{
+			Authors:         []string{"A. Author"},
+			PrimaryCategory: "cond-mat.mes-hall",
+			Published:       time.Date(2011, 1, 31, 21, 0, 0, 0, time.UTC),
+			Updated:         time.Date(2011, 5, 3, 9, 0, 0, 0, time.UTC),
+			PDF:             "http://arxiv.org/pdf/1102.0001v2",
+		},
+	}
+	for i, want := range extras {
+		extra, ok := results[i].Extra.(ArxivExtra)
+		if !ok {
+			t.Fatalf("result %d: extra = %T", i, results[i].Extra)
+		}
+		if !extra.Published.Equal(want.Published) || !extra.Updated.Equal(want.Updated) {
+			t.Errorf("result %d: published %v, updated %v, want %v, %v", i, extra.Published, extra.Updated, want.Published, want.Updated)
+		}
+		extra.Published, extra.Updated = want.Published, want.Updated
+		if !reflect.DeepEqual(extra, want) {
+			t.Errorf("result %d: extra = %+v, want %+v", i, extra, want)
+		}
+		if results[i].Date == nil || !results[i].Date.Equal(want.Published) {
+			t.Errorf("result %d: date = %v", i, results[i].Date)
+		}
+	}
+
+	// the second page reaches the 12 total results
+	if len(srv.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(srv.requests))
+	}
+	for i, start := range []string{"0", "10"} {
+		qry := srv.query(i)
+		if got := qry.Get("start"); got != start {
+			t.Errorf("request %d: start = %q, want %q", i, got, start)
+		}
+		if got := qry.Get("max_results"); got != "10" {
+			t.Errorf("request %d: max_results = %q", i, got)
+		}
+		if got := qry.Get("search_query"); got != "all:electron" {
+			t.Errorf("request %d: search_query = %q", i, got)
+		}
+	}
+}
+
+func TestArxivError(t *testing.T) {
+	srv := newFixtureServer(t, "application/atom+xml; charset=utf-8", "arxiv_error.xml")
+	srv.status = 400
+	en := ArxivAt(srv.URL + "/api/query")
+
+	_, err := en.Crawl("electron", &SearchOptions{Pages: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T %v, want *APIError", err, err)
+	}
+	if apiErr.Code != "max_results_must_be_non-negative" || apiErr.Message != "max_results must be non-negative" {
+		t.Errorf("got %+v", apiErr)
+	}
+}
+
+func TestArxivQuery(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	noon := time.Date(2024, 3, 4, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		query   string
+		options SearchOptions
+		search  string
+		sortBy  string
+	}{
+		{"quantum error correction", SearchOptions{}, "all:quantum AND all:error AND all:correction", ""},
+		{"au:hinton AND ti:boltzmann", SearchOptions{}, "au:hinton AND ti:boltzmann", ""},
+		{"electron", SearchOptions{From: &from, To: &to}, "(all:electron) AND submittedDate:[202401020000 TO 202403042359]", ""},
+		{"electron", SearchOptions{From: &from, To: &noon}, "(all:electron) AND submittedDate:[202401020000 TO 202403042359]", ""},
+		{"electron", SearchOptions{To: &to}, "(all:electron) AND submittedDate:[000001010000 TO 202403042359]", ""},
+		{"electron", SearchOptions{Sort: SortDate}, "all:electron", "submittedDate"},
+	}
+	en := Arxiv()
+	for _, test := range tests {
+		qry := parseQuery(t, en.SearchUrl(test.query, &test.options))
+		if got := qry.Get("search_query"); got != test.search {
+			t.Errorf("%q %+v: search_query = %q, want %q", test.query, test.options, got, test.search)
+		}
+		if got := qry.Get("sortBy"); got != test.sortBy {
+			t.Errorf("%q %+v: sortBy = %q, want %q", test.query, test.options, got, test.sortBy)
+		}
+	}
+
+	// without an end the range ends now
+	qry := parseQuery(t, en.SearchUrl("electron", &SearchOptions{From: &from}))
+	if got := qry.Get("search_query"); !strings.HasPrefix(got, "(all:electron) AND submittedDate:[202401020000 TO ") {
+		t.Errorf("search_query = %q", got)
+	}
+}
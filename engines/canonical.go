@@ -0,0 +1,170 @@
+package engines
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CanonicalRules selects which differences between two links are ignored
+// when deciding whether they point to the same page. The zero value only
+// treats identical links as equal.
+type CanonicalRules struct {
+	// Scheme treats http and https links as equal
+	Scheme bool
+	// WWW treats www.example.com and example.com as equal
+	WWW bool
+	// TrailingSlash ignores a trailing slash in the path
+	TrailingSlash bool
+	// Fragment ignores everything after the #
+	Fragment bool
+	// Trackers ignores the query parameters listed in TrackingParams
+	Trackers bool
+	// Encoding ignores differences in percent-encoding, the case of the
+	// host and the order of query parameters
+	Encoding bool
+}
+
+// TrackingParams lists query parameters which are only used to track
+// visitors, a trailing * matches any parameter with that prefix
+var TrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"gclsrc",
+	"msclkid",
+	"yclid",
+	"mc_cid",
+	"mc_eid",
+	"igshid",
+	"_ga",
+	"_hsenc",
+	"_hsmi",
+}
+
+var canonicalRuleNames = map[string]func(rules *CanonicalRules){
+	"scheme":   func(rules *CanonicalRules) { rules.Scheme = true },
+	"www":      func(rules *CanonicalRules) { rules.WWW = true },
+	"slash":    func(rules *CanonicalRules) { rules.TrailingSlash = true },
+	"fragment": func(rules *CanonicalRules) { rules.Fragment = true },
+	"trackers": func(rules *CanonicalRules) { rules.Trackers = true },
+	"encoding": func(rules *CanonicalRules) { rules.Encoding = true },
+}
+
+// AllCanonicalRules ignores every difference CanonicalRules knows about
+var AllCanonicalRules = CanonicalRules{
+	Scheme:        true,
+	WWW:           true,
+	TrailingSlash: true,
+	Fragment:      true,
+	Trackers:      true,
+	Encoding:      true,
+}
+
+// ParseCanonicalRules parses a comma separated list of the rule names scheme,
+// www, slash, fragment, trackers and encoding. "all" enables every rule and
+// "none" or an empty string none of them.
+func ParseCanonicalRules(str string) (CanonicalRules, error) {
+	var rules CanonicalRules
+	for _, name := range strings.Split(str, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "", "none":
+		case "all":
+			rules = AllCanonicalRules
+		default:
+			enable, ok := canonicalRuleNames[name]
+			if !ok {
+				return rules, fmt.Errorf("unknown canonicalization rule %q", name)
+			}
+			enable(&rules)
+		}
+	}
+	return rules, nil
+}
+
+// Canonical returns the form of link used to detect duplicates under the rules
+func (rules *CanonicalRules) Canonical(link string) string {
+	if *rules == (CanonicalRules{}) {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	if rules.Scheme && u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	if rules.Encoding {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		u.Host = strings.TrimSuffix(u.Host, ":80")
+		u.Host = strings.TrimSuffix(u.Host, ":443")
+		u.RawPath = ""
+	}
+	if rules.WWW {
+		u.Host = strings.TrimPrefix(u.Host, "www.")
+	}
+	if rules.TrailingSlash {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	if rules.Fragment {
+		u.Fragment = ""
+		u.RawFragment = ""
+	}
+	if rules.Trackers {
+		u.RawQuery = removeTrackers(u.RawQuery)
+	}
+	if rules.Encoding && u.RawQuery != "" {
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String()
+}
+
+// CleanLink removes tracking parameters from link
+func CleanLink(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	u.RawQuery = removeTrackers(u.RawQuery)
+	return u.String()
+}
+
+// removeTrackers drops tracking parameters from a raw query while leaving
+// all other parameters untouched
+func removeTrackers(rawQuery string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+	var kept []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		name := pair
+		if i := strings.Index(pair, "="); i >= 0 {
+			name = pair[:i]
+		}
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !isTracker(name) {
+			kept = append(kept, pair)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+func isTracker(param string) bool {
+	param = strings.ToLower(param)
+	for _, tracker := range TrackingParams {
+		if strings.HasSuffix(tracker, "*") {
+			if strings.HasPrefix(param, strings.TrimSuffix(tracker, "*")) {
+				return true
+			}
+		} else if param == tracker {
+			return true
+		}
+	}
+	return false
+}
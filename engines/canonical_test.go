@@ -0,0 +1,112 @@
+package engines
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		rules CanonicalRules
+		a, b  string
+		equal bool
+	}{
+		// without rules only identical links are equal
+		{CanonicalRules{}, "https://example.org/page", "https://example.org/page", true},
+		{CanonicalRules{}, "http://example.org/page", "https://example.org/page", false},
+		{CanonicalRules{}, "https://example.org/page/", "https://example.org/page", false},
+
+		{CanonicalRules{Scheme: true}, "http://example.org/page", "https://example.org/page", true},
+		{CanonicalRules{Scheme: true}, "ftp://example.org/page", "https://example.org/page", false},
+		{CanonicalRules{WWW: true}, "https://www.example.org/page", "https://example.org/page", true},
+		{CanonicalRules{WWW: true}, "https://www2.example.org/page", "https://example.org/page", false},
+		{CanonicalRules{TrailingSlash: true}, "https://example.org/page/", "https://example.org/page", true},
+		{CanonicalRules{TrailingSlash: true}, "https://example.org/", "https://example.org", true},
+		{CanonicalRules{Fragment: true}, "https://example.org/page#section", "https://example.org/page", true},
+		{CanonicalRules{Fragment: true}, "https://example.org/page#", "https://example.org/page", true},
+		{CanonicalRules{Trackers: true}, "https://example.org/page?utm_source=news&utm_medium=email", "https://example.org/page", true},
+		{CanonicalRules{Trackers: true}, "https://example.org/page?id=1&fbclid=IwAR0abc", "https://example.org/page?id=1", true},
+		{CanonicalRules{Trackers: true}, "https://example.org/page?id=1", "https://example.org/page?id=2", false},
+		{CanonicalRules{Encoding: true}, "https://example.org/a%7Eb", "https://example.org/a~b", true},
+		{CanonicalRules{Encoding: true}, "https://EXAMPLE.org:443/page", "https://example.org/page", true},
+		{CanonicalRules{Encoding: true}, "https://example.org/page?b=2&a=1", "https://example.org/page?a=1&b=2", true},
+		{CanonicalRules{Encoding: true}, "https://example.org/page?q=a%20b", "https://example.org/page?q=a+b", true},
+		{CanonicalRules{Encoding: true}, "https://example.org/Page", "https://example.org/page", false},
+
+		// only the enabled rules apply
+		{CanonicalRules{Scheme: true}, "http://www.example.org/page", "https://example.org/page", false},
+		{AllCanonicalRules, "http://WWW.example.org/page/?utm_campaign=x&b=2&a=1#top", "https://example.org/page?a=1&b=2", true},
+		{AllCanonicalRules, "https://example.org/page?a=1", "https://example.org/other?a=1", false},
+
+		// links which aren't absolute urls are left alone
+		{AllCanonicalRules, "/page", "/page/", false},
+		{AllCanonicalRules, "not a link", "not a link", true},
+	}
+	for _, test := range tests {
+		a, b := test.rules.Canonical(test.a), test.rules.Canonical(test.b)
+		if (a == b) != test.equal {
+			t.Errorf("%+v: %s and %s became %s and %s, equal = %v", test.rules, test.a, test.b, a, b, test.equal)
+		}
+	}
+}
+
+func TestParseCanonicalRules(t *testing.T) {
+	tests := []struct {
+		str   string
+		rules CanonicalRules
+	}{
+		{"", CanonicalRules{}},
+		{"none", CanonicalRules{}},
+		{"all", AllCanonicalRules},
+		{"scheme", CanonicalRules{Scheme: true}},
+		{"www, slash", CanonicalRules{WWW: true, TrailingSlash: true}},
+		{"fragment,trackers,encoding", CanonicalRules{Fragment: true, Trackers: true, Encoding: true}},
+		{"none,all", AllCanonicalRules},
+	}
+	for _, test := range tests {
+		rules, err := ParseCanonicalRules(test.str)
+		if err != nil {
+			t.Errorf("%q: %v", test.str, err)
+		} else if rules != test.rules {
+			t.Errorf("%q: got %+v, want %+v", test.str, rules, test.rules)
+		}
+	}
+
+	for _, str := range []string{"host", "scheme,query", "ALL"} {
+		if _, err := ParseCanonicalRules(str); err == nil {
+			t.Errorf("%q: no error for an unknown rule", str)
+		}
+	}
+}
+
+func TestCleanLink(t *testing.T) {
+	tests := map[string]string{
+		"https://example.org/page":                              "https://example.org/page",
+		"https://example.org/page?utm_source=a&utm_medium=b":    "https://example.org/page",
+		"https://example.org/page?id=1&fbclid=IwAR0abc&gclid=x": "https://example.org/page?id=1",
+		"https://example.org/page?UTM_Source=a&id=1":            "https://example.org/page?id=1",
+		"https://example.org/page?utm%5Fsource=a&id=1":          "https://example.org/page?id=1",
+		"https://example.org/page?q=a%20b&b=2&a=1&_ga=1":        "https://example.org/page?q=a%20b&b=2&a=1",
+		"https://example.org/page?utm=1&utmost=2":               "https://example.org/page?utm=1&utmost=2",
+		"https://example.org/page?mc_cid=1#utm_source=fragment": "https://example.org/page#utm_source=fragment",
+		"https://example.org/page?flag&utm_content":             "https://example.org/page?flag",
+	}
+	for link, want := range tests {
+		if got := CleanLink(link); got != want {
+			t.Errorf("%s: got %s, want %s", link, got, want)
+		}
+	}
+}
+
+func TestRemoveTrackers(t *testing.T) {
+	tests := map[string]string{
+		"":                           "",
+		"utm_source=a":               "",
+		"a=1&utm_term=x&b=%2F&c":     "a=1&b=%2F&c",
+		"yclid=1&msclkid=2&igshid=3": "",
+		"_hsenc=1&_hsmi=2&x=_ga":     "x=_ga",
+		"dclid=1&=empty":             "=empty",
+	}
+	for rawQuery, want := range tests {
+		if got := removeTrackers(rawQuery); got != want {
+			t.Errorf("%q: got %q, want %q", rawQuery, got, want)
+		}
+	}
+}
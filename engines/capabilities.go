@@ -0,0 +1,121 @@
+package engines
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pagination styles an engine can use, as declared in Capabilities.Pagination
+const (
+	PaginationNone   = "none"
+	PaginationLink   = "link"
+	PaginationPage   = "page"
+	PaginationOffset = "offset"
+)
+
+// Capabilities declares which search options an engine supports
+type Capabilities struct {
+	// Timeranges maps every supported time range to the one the engine
+	// actually uses, which is coarser than the requested one for engines
+	// that don't support it exactly. Missing time ranges are ignored.
+	Timeranges map[string]string `xml:"-"`
+	DateRange  bool
+	// DateRangeYears reports whether custom date ranges are rounded to
+	// whole years
+	DateRangeYears bool
+	Languages      bool
+	SafeSearch     bool
+	// Sort reports whether results can be sorted by date
+	Sort bool
+	// Sites reports whether the site to search can be selected
+	Sites      bool
+	Pagination string
+}
+
+// Warning describes a search option an engine ignores or can only partly honor
+type Warning struct {
+	Engine  string
+	Option  string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Engine, w.Message)
+}
+
+func everyTimerange() map[string]string {
+	return map[string]string{
+		"hour":  "hour",
+		"day":   "day",
+		"week":  "week",
+		"month": "month",
+		"year":  "year",
+	}
+}
+
+// timerange returns the time range to search in, or an empty string if the
+// search should not be restricted
+func (c *Capabilities) timerange(options *SearchOptions) string {
+	return c.Timeranges[options.Timerange]
+}
+
+// Check returns a warning for every option in options the engine ignores or
+// replaces with something else
+func (en *SearchEngine) Check(options *SearchOptions) []Warning {
+	caps := &en.Info.Capabilities
+	var warnings []Warning
+	warn := func(option string, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{
+			Engine:  en.Info.Name,
+			Option:  option,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	dateRange := options.From != nil || options.To != nil
+	if dateRange && !caps.DateRange {
+		warn("daterange", "custom date ranges are not supported and will be ignored")
+	}
+	if dateRange && caps.DateRange && caps.DateRangeYears && !wholeYears(options.From, options.To) {
+		warn("daterange", "date range rounded to whole years")
+	}
+	if options.Timerange != "" && options.Timerange != "any" {
+		timerange, ok := caps.Timeranges[options.Timerange]
+		switch {
+		case dateRange && caps.DateRange:
+			warn("timerange", "time range %q is ignored in favour of the custom date range", options.Timerange)
+		case !ok:
+			warn("timerange", "time range %q is not supported and will be ignored", options.Timerange)
+		case timerange != options.Timerange:
+			warn("timerange", "time range %q is not supported, searching the last %s instead", options.Timerange, timerange)
+		}
+	}
+	if options.Lang != "" && !caps.Languages {
+		warn("lang", "language selection is not supported and will be ignored")
+	}
+	if options.SafeSearch && !caps.SafeSearch {
+		warn("safesearch", "safe search is not supported and will be ignored")
+	}
+	if options.Sort == SortDate && !caps.Sort {
+		warn("sort", "sorting by date is not supported, results are sorted by relevance")
+	}
+	if options.Site != "" && !caps.Sites {
+		warn("site", "site selection is not supported and will be ignored")
+	}
+	if (options.Pages > 1 || options.Pages == -1) && caps.Pagination == PaginationNone {
+		warn("pages", "pagination is not supported, only the first page will be searched")
+	}
+	return warnings
+}
+
+// wholeYears reports whether the date range from from to to starts and ends
+// on year boundaries, nil meaning unbounded
+func wholeYears(from *time.Time, to *time.Time) bool {
+	if from != nil && (from.Month() != time.January || from.Day() != 1) {
+		return false
+	}
+	if to != nil && (to.Month() != time.December || to.Day() != 31) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package engines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckDateRangeYears(t *testing.T) {
+	tests := []struct {
+		from, to *time.Time
+		rounded  bool
+	}{
+		{dateOf(2020, 1, 1), dateOf(2023, 12, 31), false},
+		{dateOf(2020, 1, 1), nil, false},
+		{nil, dateOf(2023, 12, 31), false},
+		{dateOf(2020, 3, 15), dateOf(2023, 12, 31), true},
+		{dateOf(2020, 1, 1), dateOf(2023, 6, 30), true},
+		{nil, dateOf(2024, 1, 1), true},
+	}
+	scholar := Scholar()
+	arxiv := Arxiv()
+	for _, test := range tests {
+		options := &SearchOptions{From: test.from, To: test.to}
+		warnings := scholar.Check(options)
+		if test.rounded && (len(warnings) != 1 || warnings[0].Option != "daterange" || warnings[0].Message != "date range rounded to whole years") {
+			t.Errorf("%v to %v: got warnings %v, want the date range to be rounded", test.from, test.to, warnings)
+		}
+		if !test.rounded && len(warnings) != 0 {
+			t.Errorf("%v to %v: got warnings %v, want none", test.from, test.to, warnings)
+		}
+		if warnings := arxiv.Check(options); len(warnings) != 0 {
+			t.Errorf("%v to %v: got arxiv warnings %v, want none", test.from, test.to, warnings)
+		}
+	}
+}
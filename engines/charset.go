@@ -0,0 +1,56 @@
+package engines
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gocolly/colly"
+	"github.com/saintfish/chardet"
+	"golang.org/x/net/html/charset"
+)
+
+// fixCharset converts the body of r to UTF-8 if it isn't valid UTF-8 yet,
+// which happens when a server declares no or the wrong charset. chardet is
+// used to detect the actual charset, preferring any of the given charsets
+// it considers possible over its best guess.
+func fixCharset(r *colly.Response, preferred []string) error {
+	if utf8.Valid(r.Body) {
+		return nil
+	}
+	candidates, err := chardet.NewTextDetector().DetectAll(r.Body)
+	if err != nil {
+		return err
+	}
+	name := candidates[0].Charset
+	for _, candidate := range candidates {
+		if containsFold(preferred, candidate.Charset) {
+			name = candidate.Charset
+			break
+		}
+	}
+
+	// chardet and the WHATWG encoding labels disagree on this one
+	if name == "GB-18030" {
+		name = "gb18030"
+	}
+	enc, _ := charset.Lookup(name)
+	if enc == nil {
+		return fmt.Errorf("unsupported charset %s", name)
+	}
+	body, err := enc.NewDecoder().Bytes(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = body
+	return nil
+}
+
+func containsFold(list []string, str string) bool {
+	for _, el := range list {
+		if strings.EqualFold(el, str) {
+			return true
+		}
+	}
+	return false
+}
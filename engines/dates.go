@@ -0,0 +1,90 @@
+package engines
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var relativeAge = regexp.MustCompile(`^(\d+|an?)\s+(second|minute|hour|day|week|month|year)s?\s+ago$`)
+
+// absoluteAgeLayouts lists the layouts engines use to show absolute dates
+var absoluteAgeLayouts = []string{
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"2006-01-02",
+}
+
+// parseAge parses the age engines show next to results, either relative to
+// now like "3 days ago" or absolute like "Jan 2, 2006". It returns nil if str
+// is in neither form.
+func parseAge(str string, now time.Time) *time.Time {
+	str = strings.ToLower(strings.TrimSpace(str))
+	if str == "" {
+		return nil
+	}
+	if match := relativeAge.FindStringSubmatch(str); match != nil {
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			n = 1
+		}
+		var date time.Time
+		switch match[2] {
+		case "second":
+			date = now.Add(-time.Duration(n) * time.Second)
+		case "minute":
+			date = now.Add(-time.Duration(n) * time.Minute)
+		case "hour":
+			date = now.Add(-time.Duration(n) * time.Hour)
+		case "day":
+			date = now.AddDate(0, 0, -n)
+		case "week":
+			date = now.AddDate(0, 0, -7*n)
+		case "month":
+			date = now.AddDate(0, -n, 0)
+		case "year":
+			date = now.AddDate(-n, 0, 0)
+		}
+		return &date
+	}
+	for _, layout := range absoluteAgeLayouts {
+		if date, err := time.Parse(layout, str); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
+
+// searchPeriod returns the start and end of the period to search in, taken
+// from the custom date range or otherwise the time range of options. The end
+// of a custom date range is the last second of its day, as the day itself is
+// included. Zero times are unbounded.
+func searchPeriod(caps *Capabilities, options *SearchOptions) (time.Time, time.Time) {
+	var from, to time.Time
+	if options.From != nil || options.To != nil {
+		if options.From != nil {
+			from = *options.From
+		}
+		if options.To != nil {
+			to = options.To.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return from, to
+	}
+	now := time.Now()
+	switch caps.timerange(options) {
+	case "hour":
+		from = now.Add(-time.Hour)
+	case "day":
+		from = now.AddDate(0, 0, -1)
+	case "week":
+		from = now.AddDate(0, 0, -7)
+	case "month":
+		from = now.AddDate(0, -1, 0)
+	case "year":
+		from = now.AddDate(-1, 0, 0)
+	}
+	return from, to
+}
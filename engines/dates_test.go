@@ -0,0 +1,61 @@
+package engines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSearchPeriod(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	caps := &Capabilities{Timeranges: everyTimerange()}
+
+	start, end := searchPeriod(caps, &SearchOptions{From: &from, To: &to, Timerange: "week"})
+	if !start.Equal(from) {
+		t.Errorf("start = %v, want %v", start, from)
+	}
+	if want := time.Date(2024, 3, 4, 23, 59, 59, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %v, want %v", end, want)
+	}
+
+	start, end = searchPeriod(caps, &SearchOptions{To: &to})
+	if !start.IsZero() {
+		t.Errorf("start = %v, want none", start)
+	}
+	if end.Unix() != to.Unix()+24*60*60-1 {
+		t.Errorf("end = %v, want the end of %v", end, to)
+	}
+
+	before := time.Now()
+	start, end = searchPeriod(caps, &SearchOptions{Timerange: "week"})
+	if start.Before(before.AddDate(0, 0, -7)) || start.After(time.Now().AddDate(0, 0, -7)) {
+		t.Errorf("start = %v, want a week ago", start)
+	}
+	if !end.IsZero() {
+		t.Errorf("end = %v, want none", end)
+	}
+
+	start, end = searchPeriod(caps, &SearchOptions{})
+	if !start.IsZero() || !end.IsZero() {
+		t.Errorf("got %v to %v, want no period", start, end)
+	}
+}
+
+func TestDateRangeEndsIncluded(t *testing.T) {
+	to := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	end := "1709596799"
+	options := &SearchOptions{To: &to}
+
+	hn := HackerNews()
+	if got := parseQuery(t, hn.SearchUrl("golang", options)).Get("numericFilters"); got != "created_at_i<="+end {
+		t.Errorf("hackernews: numericFilters = %q", got)
+	}
+	se := StackExchange()
+	if got := parseQuery(t, se.SearchUrl("golang", options)).Get("todate"); got != end {
+		t.Errorf("stackexchange: todate = %q", got)
+	}
+	baidu := Baidu()
+	if got := parseQuery(t, baidu.SearchUrl("golang", options)).Get("gpc"); got != "stf=0,"+end+"|stftype=2" {
+		t.Errorf("baidu: gpc = %q", got)
+	}
+}
@@ -0,0 +1,311 @@
+package engines
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+// Definition describes a search engine whose result pages are scraped using
+// css selectors, so that engines can be added or fixed without rebuilding googly.
+//
+// Type selects another kind of engine, which only needs a Url:
+//
+//	arxiv      the arXiv query API or a mirror of it at Url
+//	elasticsearch
+//	           an Elasticsearch or OpenSearch cluster at Url, the index to
+//	           search is described by Elasticsearch. Its credentials may
+//	           reference environment variables like $ES_API_KEY.
+//	mediawiki  a MediaWiki instance, Url points to its api.php
+//	searxng    a SearXNG instance with the json format enabled, Url is its
+//	           base url and Mirrors lists further instances to fail over to
+//	opensearch a site with an OpenSearch description document at Url
+//
+// Url and the values of Params may contain the placeholders {query}, {lang}
+// and {timerange}, which are replaced by the search query, the search
+// language and the value Timeranges maps the requested time range to.
+// Parameters which end up empty are left out. SafeSearch lists additional
+// parameters sent when safe search is enabled.
+type Definition struct {
+	Type        string            `json:"type"`
+	Name        string            `json:"name"`
+	Short       string            `json:"short"`
+	DisplayName string            `json:"display_name"`
+	Homepage    string            `json:"homepage"`
+	Url         string            `json:"url"`
+	Params      map[string]string `json:"params"`
+	Timeranges  map[string]string `json:"timeranges"`
+	SafeSearch  map[string]string `json:"safe_search"`
+	Selectors   struct {
+		Result      string `json:"result"`
+		Title       string `json:"title"`
+		Link        string `json:"link"`
+		LinkAttr    string `json:"link_attr"`
+		Description string `json:"description"`
+		Pagination  string `json:"pagination"`
+	} `json:"selectors"`
+	// Pagination describes how the next page is requested. Without a Param
+	// the href of the element matched by the pagination selector is followed,
+	// otherwise Param is set to Start + (page - 1) * Step on the current url.
+	Pagination struct {
+		Param string `json:"param"`
+		Start int    `json:"start"`
+		Step  int    `json:"step"`
+	} `json:"pagination"`
+	// Redirect describes redirect links whose target is passed in the query
+	// parameter Param, on the given Path
+	Redirect struct {
+		Path  string `json:"path"`
+		Param string `json:"param"`
+	} `json:"redirect"`
+	// Browsers lists the browsers to generate user agents for: chrome,
+	// firefox, opera, chrome-mobile and firefox-mobile
+	Browsers []string `json:"browsers"`
+	Mirrors  []string `json:"mirrors"`
+	// Elasticsearch describes the index searched by elasticsearch engines
+	Elasticsearch ElasticsearchConfig `json:"elasticsearch"`
+}
+
+// definitionTypes creates the engines for the types a definition can have
+// besides scraped html
+var definitionTypes = map[string]func(def *Definition) SearchEngine{
+	"arxiv": func(def *Definition) SearchEngine {
+		return ArxivAt(def.Url)
+	},
+	"elasticsearch": func(def *Definition) SearchEngine {
+		config := def.Elasticsearch
+		config.URL = def.Url
+		config.Username = os.ExpandEnv(config.Username)
+		config.Password = os.ExpandEnv(config.Password)
+		config.APIKey = os.ExpandEnv(config.APIKey)
+		return Elasticsearch(config)
+	},
+	"mediawiki": func(def *Definition) SearchEngine {
+		return MediaWiki(def.Url)
+	},
+	"opensearch": func(def *Definition) SearchEngine {
+		return OpenSearch(def.Url)
+	},
+	"searxng": func(def *Definition) SearchEngine {
+		return SearXNG(append([]string{def.Url}, def.Mirrors...)...)
+	},
+}
+
+// LoadDefinitionFile reads a json engine definition and registers it,
+// replacing any engine with the same name
+func LoadDefinitionFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := def.validate(); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	Register(def.Name, def.SearchEngine)
+	return nil
+}
+
+// LoadDefinitions registers all json engine definitions in dir. A missing
+// directory is not an error.
+func LoadDefinitions(dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := LoadDefinitionFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (def *Definition) validate() error {
+	switch {
+	case def.Name == "":
+		return fmt.Errorf("engine definition is missing a name")
+	case def.Name == "combined":
+		return fmt.Errorf("engine name %q is reserved", def.Name)
+	case def.Url == "":
+		return fmt.Errorf("engine definition %q is missing a url", def.Name)
+	case def.Type == "elasticsearch" && def.Elasticsearch.Index == "":
+		return fmt.Errorf("engine definition %q is missing the index to search", def.Name)
+	case def.Type != "":
+		if _, ok := definitionTypes[def.Type]; !ok {
+			return fmt.Errorf("engine definition %q has unknown type %q", def.Name, def.Type)
+		}
+	case def.Selectors.Result == "":
+		return fmt.Errorf("engine definition %q is missing a result selector", def.Name)
+	}
+	if def.Redirect.Param != "" && def.Redirect.Path == "" {
+		return fmt.Errorf("engine definition %q is missing the path of its redirect links", def.Name)
+	}
+	for _, browser := range def.Browsers {
+		if _, ok := browsers[browser]; !ok {
+			return fmt.Errorf("engine definition %q uses unknown browser %q", def.Name, browser)
+		}
+	}
+	return nil
+}
+
+// SearchEngine creates a search engine from the definition
+func (def *Definition) SearchEngine() SearchEngine {
+	displayName := def.DisplayName
+	if displayName == "" {
+		displayName = def.Name
+	}
+	short := def.Short
+	if short == "" {
+		short = strings.ToUpper(def.Name[:1])
+	}
+
+	if create, ok := definitionTypes[def.Type]; ok {
+		en := create(def)
+		en.Info.Name = def.Name
+		en.Info.Short = short
+		en.Info.DisplayName = displayName
+		if def.Homepage != "" {
+			en.Info.Homepage = def.Homepage
+		}
+		if len(def.Browsers) > 0 {
+			en.browserConfig = browserConfig(def.Browsers)
+		}
+		return en
+	}
+
+	linkAttr := def.Selectors.LinkAttr
+	if linkAttr == "" {
+		linkAttr = "href"
+	}
+	step := def.Pagination.Step
+	if step == 0 {
+		step = 1
+	}
+
+	var decodeLink func(link string) (string, bool)
+	if def.Redirect.Param != "" {
+		decodeLink = queryParamDecoder(def.Redirect.Path, def.Redirect.Param)
+	}
+
+	return SearchEngine{
+		Info: Info{
+			Name:         def.Name,
+			Short:        short,
+			DisplayName:  displayName,
+			Homepage:     def.Homepage,
+			Capabilities: def.capabilities(),
+		},
+		browserConfig: browserConfig(def.Browsers),
+		SearchUrl: func(query string, options *SearchOptions) string {
+			replacer := strings.NewReplacer(
+				"{query}", query,
+				"{lang}", options.Lang,
+				"{timerange}", def.Timeranges[options.Timerange],
+			)
+			pathReplacer := strings.NewReplacer(
+				"{query}", url.PathEscape(query),
+				"{lang}", url.PathEscape(options.Lang),
+				"{timerange}", url.PathEscape(def.Timeranges[options.Timerange]),
+			)
+			queryReplacer := strings.NewReplacer(
+				"{query}", url.QueryEscape(query),
+				"{lang}", url.QueryEscape(options.Lang),
+				"{timerange}", url.QueryEscape(def.Timeranges[options.Timerange]),
+			)
+			params := url.Values{}
+			for name, value := range def.Params {
+				params.Set(name, replacer.Replace(value))
+			}
+			if options.SafeSearch {
+				for name, value := range def.SafeSearch {
+					params.Set(name, value)
+				}
+			}
+			// placeholders in the query part of the url need to be escaped
+			// differently than those in its path
+			link := pathReplacer.Replace(def.Url)
+			if i := strings.Index(def.Url, "?"); i >= 0 {
+				link = pathReplacer.Replace(def.Url[:i]) + "?" + queryReplacer.Replace(def.Url[i+1:])
+			}
+			return getUrl(link, "", params)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			return Result{
+				Title:       e.ChildText(def.Selectors.Title),
+				Link:        e.Request.AbsoluteURL(e.ChildAttr(def.Selectors.Link, linkAttr)),
+				Description: e.ChildText(def.Selectors.Description),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			if def.Pagination.Param == "" {
+				return e.Request.AbsoluteURL(e.Attr("href"))
+			}
+			u := *e.Request.URL
+			qry := u.Query()
+			qry.Set(def.Pagination.Param, strconv.Itoa(def.Pagination.Start+(page-1)*step))
+			u.RawQuery = qry.Encode()
+			return u.String()
+		},
+		DecodeLink:         decodeLink,
+		resultSelector:     def.Selectors.Result,
+		paginationSelector: def.Selectors.Pagination,
+	}
+}
+
+func (def *Definition) capabilities() Capabilities {
+	caps := Capabilities{
+		Timeranges: map[string]string{},
+		SafeSearch: len(def.SafeSearch) > 0,
+		Pagination: PaginationNone,
+	}
+	for timerange := range def.Timeranges {
+		caps.Timeranges[timerange] = timerange
+	}
+	caps.Languages = strings.Contains(def.Url, "{lang}")
+	for _, value := range def.Params {
+		caps.Languages = caps.Languages || strings.Contains(value, "{lang}")
+	}
+	switch {
+	case def.Selectors.Pagination == "":
+	case def.Pagination.Param == "":
+		caps.Pagination = PaginationLink
+	case def.Pagination.Step > 1:
+		caps.Pagination = PaginationOffset
+	default:
+		caps.Pagination = PaginationPage
+	}
+	return caps
+}
+
+var browsers = map[string]func(config *BrowserConfig){
+	"chrome":         func(config *BrowserConfig) { config.chrome = true },
+	"firefox":        func(config *BrowserConfig) { config.firefox = true },
+	"opera":          func(config *BrowserConfig) { config.opera = true },
+	"chrome-mobile":  func(config *BrowserConfig) { config.chromeM = true },
+	"firefox-mobile": func(config *BrowserConfig) { config.firefoxM = true },
+}
+
+func browserConfig(names []string) BrowserConfig {
+	config := BrowserConfig{}
+	if len(names) == 0 {
+		names = []string{"chrome", "firefox"}
+	}
+	for _, name := range names {
+		browsers[name](&config)
+	}
+	return config
+}
@@ -0,0 +1,173 @@
+package engines
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// ElasticsearchConfig describes an Elasticsearch or OpenSearch index to search
+type ElasticsearchConfig struct {
+	// URL is the base url of the cluster, e.g. http://localhost:9200
+	URL   string `json:"-"`
+	Index string `json:"index"`
+	// Query is the search request body, in which the placeholder {{query}}
+	// is replaced by the search query. By default the title and
+	// description fields are searched.
+	Query json.RawMessage `json:"query"`
+	// Fields maps result fields to the fields of the indexed documents,
+	// nested fields are separated by dots
+	Fields struct {
+		Title       string `json:"title"`
+		Link        string `json:"link"`
+		Description string `json:"description"`
+		Date        string `json:"date"`
+	} `json:"fields"`
+	// Username and Password are used for basic authentication, APIKey is
+	// used instead if set
+	Username string `json:"username"`
+	Password string `json:"password"`
+	APIKey   string `json:"api_key"`
+}
+
+// elasticsearchResponse is the part of a _search response googly uses
+type elasticsearchResponse struct {
+	Error *struct {
+		Type   string
+		Reason string
+	}
+	Hits struct {
+		Hits []struct {
+			Source map[string]interface{} `json:"_source"`
+		}
+	}
+}
+
+const elasticsearchPageSize = 10
+
+// Elasticsearch searches an Elasticsearch or OpenSearch index
+func Elasticsearch(config ElasticsearchConfig) SearchEngine {
+	fields := config.Fields
+	if fields.Title == "" {
+		fields.Title = "title"
+	}
+	if fields.Link == "" {
+		fields.Link = "url"
+	}
+	if fields.Description == "" {
+		fields.Description = "description"
+	}
+	template := string(config.Query)
+	if template == "" {
+		template = fmt.Sprintf(`{"query":{"multi_match":{"query":"{{query}}","fields":[%q,%q]}}}`, fields.Title, fields.Description)
+	}
+	base := config.URL
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	Url := func(path string, lang string) string {
+		return getUrl(base, path, nil)
+	}
+	caps := Capabilities{
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "elasticsearch",
+			Short:        "ES",
+			DisplayName:  "Elasticsearch",
+			Homepage:     config.URL,
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("from", "0")
+			params.Set("size", strconv.Itoa(elasticsearchPageSize))
+			return Url(url.PathEscape(config.Index)+"/_search?"+params.Encode(), options.Lang)
+		},
+		requestBody: func(query string, options *SearchOptions) []byte {
+			// insert the query as the content of a json string
+			escaped, _ := json.Marshal(query)
+			return []byte(strings.Replace(template, "{{query}}", string(escaped[1:len(escaped)-1]), -1))
+		},
+		prepareRequest: func(r *colly.Request, options *SearchOptions) {
+			r.Headers.Set("Content-Type", "application/json")
+			if config.APIKey != "" {
+				r.Headers.Set("Authorization", "ApiKey "+config.APIKey)
+			} else if config.Username != "" {
+				credentials := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + config.Password))
+				r.Headers.Set("Authorization", "Basic "+credentials)
+			}
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res elasticsearchResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+			if res.Error != nil {
+				return nil, "", &APIError{URL: r.Request.URL.String(), Code: res.Error.Type, Message: res.Error.Reason}
+			}
+
+			var results []Result
+			for _, hit := range res.Hits.Hits {
+				result := Result{
+					Title:       sourceField(hit.Source, fields.Title),
+					Link:        sourceField(hit.Source, fields.Link),
+					Description: sourceField(hit.Source, fields.Description),
+				}
+				if fields.Date != "" {
+					if date, err := time.Parse(time.RFC3339, sourceField(hit.Source, fields.Date)); err == nil {
+						result.Date = &date
+					}
+				}
+				results = append(results, result)
+			}
+			if len(results) < elasticsearchPageSize {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("from", strconv.Itoa(page*elasticsearchPageSize))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
+
+// sourceField returns the value of the field at the dot separated path in
+// an indexed document as a string
+func sourceField(source map[string]interface{}, path string) string {
+	var value interface{} = source
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = object[name]
+	}
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		var values []string
+		for _, el := range value {
+			values = append(values, fmt.Sprint(el))
+		}
+		return strings.Join(values, " ")
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,153 @@
+package engines
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// elasticsearchServer answers searches with total hits, taking paging from
+// the from and size parameters, and records the requests it receives
+func elasticsearchServer(t *testing.T, total int, requests *[]*http.Request, bodies *[][]byte) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, r)
+		*bodies = append(*bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/docs/_search" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"root_cause":[{"type":"index_not_found_exception","reason":"no such index [missing]"}],"type":"index_not_found_exception","reason":"no such index [missing]","index":"missing"},"status":404}`)
+			return
+		}
+		from, _ := strconv.Atoi(r.URL.Query().Get("from"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		var hits []string
+		for i := from; i < total && i < from+size; i++ {
+			hits = append(hits, fmt.Sprintf(`{"_index":"docs","_id":"%d","_score":1.0,"_source":{"doc":{"title":"Document %d","body":{"text":["first","second"]}},"meta":{"url":"https://docs.example.org/%d"},"published":"2024-03-12T10:00:00Z"}}`, i, i, i))
+		}
+		fmt.Fprintf(w, `{"took":1,"timed_out":false,"hits":{"total":{"value":%d,"relation":"eq"},"hits":[%s]}}`, total, strings.Join(hits, ","))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestElasticsearch(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := elasticsearchServer(t, 12, &requests, &bodies)
+	config := ElasticsearchConfig{URL: srv.URL, Index: "docs", APIKey: "c2VjcmV0"}
+	config.Fields.Title = "doc.title"
+	config.Fields.Link = "meta.url"
+	config.Fields.Description = "doc.body.text"
+	config.Fields.Date = "published"
+	en := Elasticsearch(config)
+
+	query := `say "hi" \ bye`
+	results, err := en.Crawl(query, &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 12 {
+		t.Fatalf("got %d results, want 12", len(results))
+	}
+	result := results[11]
+	if result.Title != "Document 11" || result.Link != "https://docs.example.org/11" || result.Description != "first second" {
+		t.Errorf("got %+v", result)
+	}
+	if result.Date == nil || !result.Date.Equal(time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %v", result.Date)
+	}
+
+	// the second page has less than a full page of hits
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	for i, from := range []string{"0", "10"} {
+		r := requests[i]
+		if r.Method != http.MethodPost {
+			t.Errorf("request %d: method = %s", i, r.Method)
+		}
+		if got := r.URL.Query().Get("from"); got != from {
+			t.Errorf("request %d: from = %q, want %q", i, got, from)
+		}
+		if got := r.URL.Query().Get("size"); got != "10" {
+			t.Errorf("request %d: size = %q", i, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "ApiKey c2VjcmV0" {
+			t.Errorf("request %d: authorization = %q", i, got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("request %d: content type = %q", i, got)
+		}
+		var body struct {
+			Query struct {
+				MultiMatch struct {
+					Query  string
+					Fields []string
+				} `json:"multi_match"`
+			}
+		}
+		if err := json.Unmarshal(bodies[i], &body); err != nil {
+			t.Fatalf("request %d: invalid body %s: %v", i, bodies[i], err)
+		}
+		if body.Query.MultiMatch.Query != query {
+			t.Errorf("request %d: query = %q, want %q", i, body.Query.MultiMatch.Query, query)
+		}
+		if fields := body.Query.MultiMatch.Fields; len(fields) != 2 || fields[0] != "doc.title" || fields[1] != "doc.body.text" {
+			t.Errorf("request %d: fields = %q", i, fields)
+		}
+	}
+}
+
+func TestElasticsearchBasicAuth(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := elasticsearchServer(t, 1, &requests, &bodies)
+	en := Elasticsearch(ElasticsearchConfig{
+		URL:      srv.URL,
+		Index:    "docs",
+		Query:    []byte(`{"query":{"match":{"doc.title":"{{query}}"}}}`),
+		Username: "elastic",
+		Password: "changeme",
+	})
+
+	if _, err := en.Crawl(`"quoted"`, &SearchOptions{Pages: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	user, password, ok := requests[0].BasicAuth()
+	if !ok || user != "elastic" || password != "changeme" {
+		t.Errorf("authorization = %q", requests[0].Header.Get("Authorization"))
+	}
+	if got := string(bodies[0]); got != `{"query":{"match":{"doc.title":"\"quoted\""}}}` {
+		t.Errorf("body = %s", got)
+	}
+}
+
+func TestElasticsearchMissingIndex(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := elasticsearchServer(t, 0, &requests, &bodies)
+	en := Elasticsearch(ElasticsearchConfig{URL: srv.URL, Index: "missing"})
+
+	_, err := en.Crawl("golang", &SearchOptions{Pages: 1})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %T %v, want *APIError", err, err)
+	}
+	if apiErr.Code != "index_not_found_exception" || apiErr.Message != "no such index [missing]" {
+		t.Errorf("got %+v", apiErr)
+	}
+	if ErrorKind(err) != StatusAPI {
+		t.Errorf("kind = %s", ErrorKind(err))
+	}
+}
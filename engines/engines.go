@@ -1,6 +1,8 @@
 package engines
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -16,30 +18,169 @@ type Result struct {
 	Title       string
 	Link        string
 	Description string
+	// Date is when the result was published, if the engine shows it
+	Date *time.Time `json:",omitempty" xml:",omitempty"`
+	// Extra holds details only some engines provide, e.g. MediaWikiExtra
+	Extra   interface{} `json:",omitempty" xml:",omitempty"`
+	Sources []Source    `xml:"Sources>Source"`
+}
+
+// Source records where a result was found
+type Source struct {
+	Engine string
+	Rank   int
+	Page   int
+	// Link is the link exactly as the engine returned it
+	Link string
 }
 
 type SearchEngine struct {
-	SearchUrl          func(query string, options *SearchOptions) string
-	Url                func(path string, lang string) string
-	Result             func(e *colly.HTMLElement) Result
-	Pagination         func(page int, options *SearchOptions, e *colly.HTMLElement) string
+	Info       Info
+	SearchUrl  func(query string, options *SearchOptions) string
+	Url        func(path string, lang string) string
+	Result     func(e *colly.HTMLElement) Result
+	Pagination func(page int, options *SearchOptions, e *colly.HTMLElement) string
+	Blocked    func(r *colly.Response) bool
+	// Parse extracts the results from a response of an engine which doesn't
+	// serve HTML, it is used instead of Result and Pagination. page is the
+	// number of the page r contains, the returned link points to the next
+	// page and is empty if there is none. r.Ctx is shared by all requests of
+	// a search and holds the query as "query".
+	Parse func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error)
+	// DecodeLink recovers the target of redirect links returned by the engine.
+	// It returns false if link is a redirect whose target can't be recovered
+	// without following it.
+	DecodeLink         func(link string) (string, bool)
 	resultSelector     string
 	paginationSelector string
-	browserConfig BrowserConfig
+	browserConfig      BrowserConfig
+	// charsets lists the charsets the engine is likely to use when its
+	// responses turn out not to be UTF-8
+	charsets []string
+	// prepareRequest adjusts every request before it is sent, e.g. to
+	// pass settings as cookies
+	prepareRequest func(r *colly.Request, options *SearchOptions)
+	// requestBody returns the body of the POST requests the engine is
+	// searched with, engines without it are searched using GET requests
+	requestBody func(query string, options *SearchOptions) []byte
+	// setup runs before the first request, e.g. to fetch what the engine
+	// needs to know to build its urls
+	setup func(transport http.RoundTripper, userAgent string) error
+	// capabilities returns what the engine supports once setup has run,
+	// for engines which only find out during setup
+	capabilities func() Capabilities
+	// fallback is searched instead if searching this engine fails without
+	// any results, e.g. another instance of a self-hosted engine
+	fallback *SearchEngine
 }
 
 type SearchOptions struct {
-	Lang  		string
-	Pages 		int
-	From  		*time.Time
-	To    		*time.Time
-	Timerange   string
-	UserAgent 	string
-	Verbose 	bool
+	Lang      string
+	Pages     int
+	From      *time.Time
+	To        *time.Time
+	Timerange string
+	UserAgent string
+	Verbose   bool
+	// Timeout limits how long a single engine may take, zero means no limit
+	Timeout    time.Duration
+	SafeSearch bool
+	// Merge selects how Combined merges the results of several engines,
+	// either MergeFusion (the default) or MergeRoundRobin
+	Merge string
+	// Weights scales the influence of each engine, by name, on the fused
+	// ranking. Engines without a weight get a weight of 1.
+	Weights map[string]float64
+	// RequireAll makes Combined fail if any engine fails
+	RequireAll bool
+	// Dedupe selects which links Combined considers to be duplicates
+	Dedupe CanonicalRules
+	// CleanLinks removes tracking parameters from result links
+	CleanLinks bool
+	// ResolveRedirects follows redirect links which can't be decoded offline
+	ResolveRedirects bool
+	// Sort selects the order of results, SortRelevance (the default) or SortDate
+	Sort string
+	// Site selects which site engines covering several sites search, e.g.
+	// serverfault on Stack Exchange
+	Site string
+}
+
+// Orders results can be sorted in, as selected by SearchOptions.Sort
+const (
+	SortRelevance = "relevance"
+	SortDate      = "date"
+)
+
+func init() {
+	Register("google", Google)
+	Register("ecosia", Ecosia)
+	Register("startpage", Startpage)
+	Register("yahoo", Yahoo)
+	Register("ddg", DuckDuckGo)
+	Register("naver", Naver)
+	Register("bing", Bing)
+	Register("brave", Brave)
+	Register("mojeek", Mojeek)
+	Register("qwant", Qwant)
+	Register("yandex", Yandex)
+	Register("baidu", Baidu)
+	Register("wikipedia", Wikipedia)
+	Register("scholar", Scholar)
+	Register("arxiv", Arxiv)
+	Register("hackernews", HackerNews)
+	Register("stackexchange", StackExchange)
+	Register("godev", GoDev)
 }
 
-func (en *SearchEngine) Crawl(query string, options *SearchOptions) []Result {
+func (en *SearchEngine) Crawl(query string, options *SearchOptions) ([]Result, error) {
+	return en.CrawlContext(context.Background(), query, options)
+}
+
+// CrawlContext is like Crawl but stops once ctx is done, returning the results
+// gathered until then together with the context's error.
+// Prepare runs the setup of engines which only know what they support after
+// fetching something, like OpenSearch, and updates Info.Capabilities. It does
+// nothing for other engines. Searching works without it, but options should
+// only be checked against the capabilities after it.
+func (en *SearchEngine) Prepare(ctx context.Context, options *SearchOptions) error {
+	if en.setup == nil || en.capabilities == nil {
+		return nil
+	}
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	userAgent := options.UserAgent
+	if userAgent == "" {
+		userAgent = RandomUA(&en.browserConfig)
+	}
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+	if err := en.setup(&contextTransport{ctx: ctx, transport: transport}, userAgent); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	en.Info.Capabilities = en.capabilities()
+	return nil
+}
+
+func (en *SearchEngine) CrawlContext(ctx context.Context, query string, options *SearchOptions) ([]Result, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
 	var results []Result
+	var crawlErr error
+	var page = 1
+	// redirects holds the absolute redirect links of results whose link
+	// still needs to be resolved, by index
+	redirects := make(map[int]string)
 
 	searchCollector := colly.NewCollector()
 	if options.UserAgent != "" {
@@ -51,104 +192,327 @@ func (en *SearchEngine) Crawl(query string, options *SearchOptions) []Result {
 		fmt.Println(searchCollector.UserAgent)
 	}
 
-	searchCollector.WithTransport(&http.Transport{
+	// every search gets its own connections, which are closed once done
+	httpTransport := &http.Transport{
 		DisableCompression: true,
+	}
+	defer httpTransport.CloseIdleConnections()
+	transport := &contextTransport{
+		ctx:       ctx,
+		transport: httpTransport,
+	}
+	searchCollector.WithTransport(transport)
+
+	// visit requests a page of results, all pages share crawlCtx
+	crawlCtx := colly.NewContext()
+	crawlCtx.Put("query", query)
+	visit := func(link string) error {
+		return searchCollector.Request("GET", link, nil, crawlCtx, nil)
+	}
+	if en.requestBody != nil {
+		body := en.requestBody(query, options)
+		visit = func(link string) error {
+			return searchCollector.Request("POST", link, bytes.NewReader(body), crawlCtx, nil)
+		}
+	}
+
+	if en.setup != nil {
+		if err := en.setup(transport, searchCollector.UserAgent); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+	}
+
+	// addResult records a result found in the response to request
+	addResult := func(result Result, request *colly.Request) {
+		result.Sources = []Source{{
+			Engine: en.Info.Name,
+			Rank:   len(results) + 1,
+			Page:   page,
+			Link:   result.Link,
+		}}
+		if en.DecodeLink != nil {
+			var decoded bool
+			if result.Link, decoded = en.DecodeLink(result.Link); !decoded && options.ResolveRedirects {
+				redirects[len(results)] = request.AbsoluteURL(result.Link)
+			}
+		}
+		if options.CleanLinks {
+			result.Link = CleanLink(result.Link)
+		}
+		results = append(results, result)
+	}
+
+	searchCollector.OnResponse(func(r *colly.Response) {
+		if err := fixCharset(r, en.charsets); err != nil && options.Verbose {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if crawlErr == nil && en.Blocked != nil && en.Blocked(r) {
+			crawlErr = &BlockedError{URL: r.Request.URL.String()}
+		}
+		if crawlErr != nil || en.Parse == nil {
+			return
+		}
+		parsed, next, err := en.Parse(page, options, r)
+		if err != nil {
+			if ErrorKind(err) == StatusUnknown {
+				err = &ParseError{URL: r.Request.URL.String(), Err: err}
+			}
+			crawlErr = err
+			return
+		}
+		for _, result := range parsed {
+			addResult(result, r.Request)
+		}
+		if next != "" && ctx.Err() == nil && (page < options.Pages || options.Pages == -1) {
+			page++
+			_ = visit(next)
+		}
 	})
 
 	searchCollector.OnHTML(en.resultSelector, func(e *colly.HTMLElement) {
+		if crawlErr != nil || en.Result == nil {
+			return
+		}
 		if options.Verbose {
 			c := e.DOM.Nodes[0]
 			p := e.DOM.Parent().Nodes[0]
 			fmt.Println("Selected:", c.Attr, "Parent:", p.Attr)
 		}
-		results = append(results, en.Result(e))
+		addResult(en.Result(e), e.Request)
 	})
 
-	var page = 1
 	searchCollector.OnHTML(en.paginationSelector, func(e *colly.HTMLElement) {
-		if  page < options.Pages || options.Pages == -1 {
+		if crawlErr != nil || ctx.Err() != nil {
+			return
+		}
+		if page < options.Pages || options.Pages == -1 {
 			page++
-			_ = searchCollector.Visit(en.Pagination(page, options, e))
+			_ = visit(en.Pagination(page, options, e))
 		}
 	})
 
 	searchCollector.OnRequest(func(r *colly.Request) {
+		if en.prepareRequest != nil {
+			en.prepareRequest(r, options)
+		}
 		if options.Verbose {
 			fmt.Println(r.URL)
 		}
 	})
 
 	searchCollector.OnError(func(r *colly.Response, err error) {
-		fmt.Fprintln(os.Stderr, err)
 		if options.Verbose {
 			fmt.Fprintln(os.Stderr, r)
 		}
-		os.Exit(r.StatusCode)
+		if crawlErr == nil {
+			crawlErr = responseError(r, err)
+			// APIs explain in the body what went wrong
+			if en.Parse != nil {
+				_, _, apiErr := en.Parse(page, options, r)
+				switch ErrorKind(apiErr) {
+				case StatusOK, StatusParse, StatusUnknown:
+				default:
+					crawlErr = apiErr
+				}
+			}
+		}
 	})
 
-	_ = searchCollector.Visit(en.SearchUrl(query, options))
+	err := visit(en.SearchUrl(query, options))
+
+	for i, link := range redirects {
+		if ctx.Err() != nil {
+			break
+		}
+		target, err := resolveRedirect(transport, link, searchCollector.UserAgent)
+		if err != nil {
+			if options.Verbose {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			continue
+		}
+		if options.CleanLinks {
+			target = CleanLink(target)
+		}
+		results[i].Link = target
+	}
+
+	if ctx.Err() != nil {
+		crawlErr = ctx.Err()
+	} else if crawlErr == nil {
+		crawlErr = err
+	}
+
+	if crawlErr != nil && len(results) == 0 && en.fallback != nil && ctx.Err() == nil {
+		if options.Verbose {
+			fmt.Fprintln(os.Stderr, crawlErr)
+		}
+		fallback := *en.fallback
+		fallback.Info.Name = en.Info.Name
+		return fallback.CrawlContext(ctx, query, options)
+	}
+
+	return results, crawlErr
+}
 
-	return results
+// Strategies for merging the results of several engines
+const (
+	MergeFusion     = "rrf"
+	MergeRoundRobin = "roundrobin"
+)
+
+// Combined searches all given engines in parallel and merges their results.
+// Engines which fail don't affect the others, their outcome is reported in
+// the returned statuses. An EnginesError is returned only if every engine
+// failed, or if any engine failed and options.RequireAll is set.
+func Combined(query string, options *SearchOptions, engines ...SearchEngine) ([]Result, []EngineStatus, error) {
+	return CombinedContext(context.Background(), query, options, engines...)
 }
 
-func Combined(query string, options *SearchOptions, engines ... SearchEngine) []Result {
-	ch := make(chan []Result, len(engines))
-	for _, eng := range engines {
-		go func(eng SearchEngine) {
-			ch <- eng.Crawl(query, options)
-		} (eng)
-	} 
-	var results [][]Result
+// CombinedContext is like Combined but stops all engines once ctx is done,
+// in which case the context's error is returned
+func CombinedContext(ctx context.Context, query string, options *SearchOptions, engines ...SearchEngine) ([]Result, []EngineStatus, error) {
+	type crawlResult struct {
+		index   int
+		results []Result
+		err     error
+		elapsed time.Duration
+	}
+	ch := make(chan crawlResult, len(engines))
+	for i, eng := range engines {
+		go func(i int, eng SearchEngine) {
+			start := time.Now()
+			results, err := eng.CrawlContext(ctx, query, options)
+			ch <- crawlResult{i, results, err, time.Since(start)}
+		}(i, eng)
+	}
+	results := make([][]Result, len(engines))
+	statuses := make([]EngineStatus, len(engines))
+	errs := make([]error, len(engines))
 	for i := 0; i < len(engines); i++ {
-		results = append(results, <-ch)
+		res := <-ch
+		results[res.index] = res.results
+		statuses[res.index] = NewEngineStatus(engines[res.index].Info.Name, len(res.results), res.elapsed, res.err)
+		errs[res.index] = res.err
+	}
+
+	var err error
+	failed := &EnginesError{Total: len(engines)}
+	for i, e := range errs {
+		if e != nil {
+			failed.Errors = append(failed.Errors, &EngineError{Engine: engines[i].Info.Name, Err: e})
+		}
+	}
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	} else if len(failed.Errors) > 0 && (len(failed.Errors) == len(engines) || options.RequireAll) {
+		err = failed
 	}
-	return unique(merge(results))
+
+	if options.Merge == MergeRoundRobin {
+		return unique(merge(results), &options.Dedupe), statuses, err
+	}
+	names := make([]string, len(engines))
+	for i, eng := range engines {
+		names[i] = eng.Info.Name
+	}
+	return fuse(names, results, options.Weights, &options.Dedupe), statuses, err
+}
+
+// contextTransport binds every request made through it to ctx, so that
+// cancelling ctx aborts requests which are still in flight
+type contextTransport struct {
+	ctx       context.Context
+	transport http.RoundTripper
 }
 
-func getUrl(base string, path string, lang string, langName string) string {
-	var between = ""
-	if !strings.Contains(path, "?") {
-		between = "?"
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req.WithContext(t.ctx))
+}
+
+// getUrl resolves path against base and sets params on the query string of
+// the result. Parameters already present in path are kept unless overridden,
+// empty parameters are left out.
+func getUrl(base string, path string, params url.Values) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(path)
+	if err != nil {
+		return ""
 	}
-	return fmt.Sprintf("%s/%s%s&%s=%s", base, path, between, langName, lang)
+	u = u.ResolveReference(ref)
+	qry := u.Query()
+	for name, values := range params {
+		qry.Del(name)
+		for _, value := range values {
+			if value != "" {
+				qry.Add(name, value)
+			}
+		}
+	}
+	u.RawQuery = qry.Encode()
+	return u.String()
 }
 
 func Google() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://google.com", path, lang, "hl")
+		return getUrl("https://google.com", path, url.Values{"hl": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		DateRange:  true,
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationLink,
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
+		Info: Info{
+			Name:         "google",
+			Short:        "G",
+			DisplayName:  "Google",
+			Homepage:     "https://google.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
 			firefox: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			var timebox = ""
+			params := url.Values{}
+			params.Set("q", query)
 			if options.From != nil || options.To != nil {
-				timebox += "&tbs=" + url.QueryEscape("cdr:1")
+				tbs := "cdr:1"
 				if options.From != nil {
-					timebox += url.QueryEscape(fmt.Sprintf(",cd_min:%02d/%02d/%d", options.From.Month(), options.From.Day(), options.From.Year()))
+					tbs += fmt.Sprintf(",cd_min:%02d/%02d/%d", options.From.Month(), options.From.Day(), options.From.Year())
 				}
 				if options.To != nil {
-					timebox += url.QueryEscape(fmt.Sprintf(",cd_max:%02d/%02d/%d", options.To.Month(), options.To.Day(), options.To.Year()))
+					tbs += fmt.Sprintf(",cd_max:%02d/%02d/%d", options.To.Month(), options.To.Day(), options.To.Year())
 				}
-			} else if options.Timerange != "any" {
-				timebox = "&tbs=qdr:"
-				switch options.Timerange {
+				params.Set("tbs", tbs)
+			} else {
+				switch caps.timerange(options) {
 				case "hour":
-					timebox += "h"
+					params.Set("tbs", "qdr:h")
 				case "day":
-					timebox += "d"
+					params.Set("tbs", "qdr:d")
 				case "week":
-					timebox += "w"
+					params.Set("tbs", "qdr:w")
 				case "month":
-					timebox += "m"
+					params.Set("tbs", "qdr:m")
 				case "year":
-					timebox += "y"
+					params.Set("tbs", "qdr:y")
 				}
 			}
-			return Url(fmt.Sprintf("search?q=%s%s", query, timebox), options.Lang)
+			if options.SafeSearch {
+				params.Set("safe", "active")
+			}
+			return Url("search?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
 			return Result{
@@ -160,6 +524,10 @@ func Google() SearchEngine {
 		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
 			return Url(e.Attr("href"), options.Lang)
 		},
+		Blocked: func(r *colly.Response) bool {
+			return strings.HasPrefix(r.Request.URL.Path, "/sorry/")
+		},
+		DecodeLink:         queryParamDecoder("/url", "q", "url"),
 		resultSelector:     ".g .rc",
 		paginationSelector: "a.pn",
 	}
@@ -167,36 +535,39 @@ func Google() SearchEngine {
 
 func Ecosia() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://www.ecosia.org", path, lang, "hl")
+		return getUrl("https://www.ecosia.org", path, url.Values{"hl": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "month",
+		},
+		Languages:  true,
+		Pagination: PaginationLink,
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
-			firefox: true,
-			chromeM: true,
+		Info: Info{
+			Name:         "ecosia",
+			Short:        "E",
+			DisplayName:  "Ecosia",
+			Homepage:     "https://www.ecosia.org",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
 			firefoxM: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			var timebox = ""
-			if options.Timerange != "any" {
-				timebox = "&freshness="
-				switch options.Timerange {
-				case "hour":
-					// TODO: is there really no way to do this?
-					timebox += "day"
-				case "day":
-					timebox += "day"
-				case "week":
-					timebox += "week"
-				case "month":
-					timebox += "month"
-				case "year":
-					// TODO: is there really no way to do this?
-					timebox += "month"
-				}
-			}
-			return Url(fmt.Sprintf("search?q=%s%s", query, timebox), options.Lang)
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("freshness", caps.timerange(options))
+			return Url("search?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
 			return Result{
@@ -215,34 +586,45 @@ func Ecosia() SearchEngine {
 
 func Startpage() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://www.startpage.com", path, lang, "language")
+		return getUrl("https://www.startpage.com", path, url.Values{"language": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		Languages:  true,
+		Pagination: PaginationPage,
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
-			firefox: true,
-			chromeM: true,
+		Info: Info{
+			Name:         "startpage",
+			Short:        "S",
+			DisplayName:  "Startpage",
+			Homepage:     "https://www.startpage.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
 			firefoxM: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			var timebox = ""
-			if options.Timerange != "any" {
-				timebox = "&with_date="
-				switch options.Timerange {
-				case "hour":
-					timebox += "h"
-				case "day":
-					timebox += "d"
-				case "week":
-					timebox += "w"
-				case "month":
-					timebox += "m"
-				case "year":
-					timebox += "y"
-				}
+			params := url.Values{}
+			params.Set("query", query)
+			params.Set("prfe", "36c84513558a2d34bf0d89ea505333ad761002405484af2476571afac1710d79d80647dbf3b0d6646044dd543d05df3a")
+			switch caps.timerange(options) {
+			case "hour":
+				params.Set("with_date", "h")
+			case "day":
+				params.Set("with_date", "d")
+			case "week":
+				params.Set("with_date", "w")
+			case "month":
+				params.Set("with_date", "m")
+			case "year":
+				params.Set("with_date", "y")
 			}
-			return Url(fmt.Sprintf("do/search?query=%s&prfe=36c84513558a2d34bf0d89ea505333ad761002405484af2476571afac1710d79d80647dbf3b0d6646044dd543d05df3a%s", query, timebox), options.Lang)
+			return Url("do/search?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
 			return Result{
@@ -265,47 +647,68 @@ func Startpage() SearchEngine {
 
 func Yahoo() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://search.yahoo.com", path, lang, "lang")
+		return getUrl("https://search.yahoo.com", path, url.Values{"lang": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "month",
+		},
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationLink,
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
-			firefox: true,
-			chromeM: true,
+		Info: Info{
+			Name:         "yahoo",
+			Short:        "Y",
+			DisplayName:  "Yahoo",
+			Homepage:     "https://search.yahoo.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
 			firefoxM: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			var timebox = ""
-			if options.Timerange != "any" {
-				timebox = "&fr2=time&age=1"
-				switch options.Timerange {
-				case "hour":
-					// TODO: is there really no way to do this?
-					timebox += "d&btf=d"
-				case "day":
-					timebox += "d&btf=d"
-				case "week":
-					timebox += "w&btf=w"
-				case "month":
-					timebox += "m&btf=m"
-				case "year":
-					// TODO: is there really no way to do this?
-					timebox += "m&btf=m"
-				}
+			params := url.Values{}
+			params.Set("p", query)
+			var age string
+			switch caps.timerange(options) {
+			case "day":
+				age = "d"
+			case "week":
+				age = "w"
+			case "month":
+				age = "m"
 			}
-			return Url(fmt.Sprintf("search?p=%s%s", query, timebox), options.Lang)
+			if age != "" {
+				params.Set("fr2", "time")
+				params.Set("age", "1"+age)
+				params.Set("btf", age)
+			}
+			if options.SafeSearch {
+				params.Set("vm", "r")
+			}
+			return Url("search?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
 			return Result{
 				Title:       e.ChildText("h3.title"),
-				Link:        e.ChildAttr("h3.title a", "href"), // TODO: extract actual URL from redirect URL
+				Link:        e.ChildAttr("h3.title a", "href"),
 				Description: e.ChildText("div.compText"),
 			}
 		},
 		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
 			return e.Attr("href")
 		},
+		DecodeLink:         pathSegmentDecoder("r.search.yahoo.com", "RU="),
 		resultSelector:     ".algo-sr",
 		paginationSelector: ".compPagination a.next",
 	}
@@ -313,35 +716,56 @@ func Yahoo() SearchEngine {
 
 func DuckDuckGo() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://duckduckgo.com", path, lang, "kl")
+		return getUrl("https://duckduckgo.com", path, url.Values{"kl": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "year",
+		},
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationLink,
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
-			firefox: true,
-			chromeM: true,
+		Info: Info{
+			Name:         "ddg",
+			Short:        "D",
+			DisplayName:  "DuckDuckGo",
+			Homepage:     "https://duckduckgo.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
 			firefoxM: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			var timebox = ""
-			if options.Timerange != "any" {
-				timebox = "&df="
-				switch options.Timerange {
-				case "hour":
-					// TODO: is there really no way to do this?
-					timebox += "d"
-				case "day":
-					timebox += "d"
-				case "week":
-					timebox += "w"
-				case "month":
-					timebox += "m"
-				case "year":
-					timebox += "y"
-				}
+			params := url.Values{}
+			params.Set("q", query)
+			// result page settings, e.g. k1 turns off ads and kd turns off redirect links
+			for _, setting := range []string{"kd", "kc", "kac", "k1", "kk", "kak", "kax", "kaq", "kao", "kap", "kau", "kz"} {
+				params.Set(setting, "-1")
+			}
+			switch caps.timerange(options) {
+			case "day":
+				params.Set("df", "d")
+			case "week":
+				params.Set("df", "w")
+			case "month":
+				params.Set("df", "m")
+			case "year":
+				params.Set("df", "y")
 			}
-			return Url(fmt.Sprintf("html?q=%s&kd=-1&kc=-1&kac=-1&k1=-1&kk=-1&kak=-1&kax=-1&kaq=-1&kao=-1&kap=-1&kau=-1&kz=-1%s", query, timebox), options.Lang)
+			if options.SafeSearch {
+				params.Set("kp", "1")
+			}
+			return Url("html?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
 			return Result{
@@ -361,37 +785,518 @@ func DuckDuckGo() SearchEngine {
 			url.RawQuery = qry.Encode()
 			return url.String()
 		},
+		DecodeLink:         queryParamDecoder("/l/", "uddg"),
 		resultSelector:     ".serp__results .result",
 		paginationSelector: ".nav-link [value='Next']",
 	}
 }
-// todo fix
+
 func Naver() SearchEngine {
 	Url := func(path string, lang string) string {
-		return getUrl("https://search.naver.com", path, lang, "hl")
+		return getUrl("https://search.naver.com", path, nil)
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		DateRange:  true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "naver",
+			Short:        "N",
+			DisplayName:  "Naver",
+			Homepage:     "https://search.naver.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
+			firefoxM: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("where", "webkr")
+			params.Set("query", query)
+			var period string
+			if options.From != nil || options.To != nil {
+				from, to := "19900101", time.Now().Format("20060102")
+				if options.From != nil {
+					from = options.From.Format("20060102")
+				}
+				if options.To != nil {
+					to = options.To.Format("20060102")
+				}
+				period = "from" + from + "to" + to
+			} else {
+				switch caps.timerange(options) {
+				case "hour":
+					period = "1h"
+				case "day":
+					period = "1d"
+				case "week":
+					period = "1w"
+				case "month":
+					period = "1m"
+				case "year":
+					period = "1y"
+				}
+			}
+			if period != "" {
+				params.Set("nso", "so:r,p:"+period+",a:all")
+			}
+			return Url("search.naver?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			// .dsc_txt is nested in .total_dsc on current pages
+			return Result{
+				Title:       e.ChildText("a.link_tit, a.title_link"),
+				Link:        e.ChildAttr("a.link_tit, a.title_link", "href"),
+				Description: strings.TrimSpace(e.DOM.Find(".total_dsc, .dsc_txt, .sh_web_passage").First().Text()),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("start", strconv.Itoa((page-1)*10+1))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		resultSelector:     "ul.lst_total > li.bx, ul.type01 > li",
+		paginationSelector: ".sc_page a.btn_next:not([aria-disabled='true']), .paging a.next",
+		charsets:           []string{"EUC-KR"},
+	}
+}
+
+func Bing() SearchEngine {
+	Url := func(path string, lang string) string {
+		setlang, cc, mkt := bingMarket(lang)
+		return getUrl("https://www.bing.com", path, url.Values{"setlang": {setlang}, "cc": {cc}, "mkt": {mkt}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "year",
+		},
+		DateRange:  true,
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationOffset,
+	}
+	// bing filters by the number of days since the unix epoch
+	days := func(t time.Time) int64 {
+		return t.Unix() / (24 * 60 * 60)
 	}
 	return SearchEngine{
-		browserConfig: BrowserConfig {
-			chrome: true,
+		Info: Info{
+			Name:         "bing",
+			Short:        "B",
+			DisplayName:  "Bing",
+			Homepage:     "https://www.bing.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
 			firefox: true,
-			chromeM: true,
+			opera:   true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("q", query)
+			var filter string
+			if options.From != nil || options.To != nil {
+				from, to := int64(0), days(time.Now())
+				if options.From != nil {
+					from = days(*options.From)
+				}
+				if options.To != nil {
+					to = days(*options.To)
+				}
+				filter = fmt.Sprintf("ez5_%d_%d", from, to)
+			} else {
+				switch caps.timerange(options) {
+				case "day":
+					filter = "ez1"
+				case "week":
+					filter = "ez2"
+				case "month":
+					filter = "ez3"
+				case "year":
+					now := time.Now()
+					filter = fmt.Sprintf("ez5_%d_%d", days(now.AddDate(-1, 0, 0)), days(now))
+				}
+			}
+			if filter != "" {
+				params.Set("filters", fmt.Sprintf("ex1:\"%s\"", filter))
+			}
+			if options.SafeSearch {
+				params.Set("adlt", "strict")
+			}
+			return Url("search?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			return Result{
+				Title:       e.ChildText("h2"),
+				Link:        e.ChildAttr("h2 a", "href"),
+				Description: e.ChildText(".b_caption p"),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("first", strconv.Itoa((page-1)*10+1))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		DecodeLink:         bingLinkDecoder,
+		resultSelector:     "#b_results > li.b_algo",
+		paginationSelector: "a.sb_pagN",
+	}
+}
+
+// bingMarket derives the setlang, cc and mkt parameters from a language like
+// "de" or "de-CH", the latter two are left empty without a region
+func bingMarket(lang string) (setlang string, cc string, mkt string) {
+	lang = strings.Replace(lang, "_", "-", 1)
+	parts := strings.SplitN(lang, "-", 2)
+	setlang = strings.ToLower(parts[0])
+	if len(parts) == 2 && parts[1] != "" {
+		cc = strings.ToUpper(parts[1])
+		mkt = setlang + "-" + cc
+	}
+	return setlang, cc, mkt
+}
+
+func Brave() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://search.brave.com", path, nil)
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "year",
+		},
+		DateRange:  true,
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "brave",
+			Short:        "V",
+			DisplayName:  "Brave Search",
+			Homepage:     "https://search.brave.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
 			firefoxM: true,
 		},
 		Url: Url,
 		SearchUrl: func(query string, options *SearchOptions) string {
-			return Url(fmt.Sprintf("search.naver?where=webkr&query=%s", query), options.Lang)
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("source", "web")
+			if options.From != nil || options.To != nil {
+				from, to := time.Unix(0, 0), time.Now()
+				if options.From != nil {
+					from = *options.From
+				}
+				if options.To != nil {
+					to = *options.To
+				}
+				params.Set("tf", from.Format("2006-01-02")+"to"+to.Format("2006-01-02"))
+			} else {
+				switch caps.timerange(options) {
+				case "day":
+					params.Set("tf", "pd")
+				case "week":
+					params.Set("tf", "pw")
+				case "month":
+					params.Set("tf", "pm")
+				case "year":
+					params.Set("tf", "py")
+				}
+			}
+			return Url("search?"+params.Encode(), options.Lang)
 		},
 		Result: func(e *colly.HTMLElement) Result {
+			age := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(e.ChildText(".snippet-description .t-secondary, .age")), "-"))
+			description := e.ChildText(".snippet-description, .generic-snippet .content")
+			if age != "" {
+				description = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(description, age), " -"))
+			}
 			return Result{
-				Title:       e.ChildText(".title_link"),
-				Link:        e.ChildAttr("a.title_link", "href"),
-				Description: e.ChildText(".sh_web_passage"),
+				Title:       e.ChildText(".title, .snippet-title"),
+				Link:        e.ChildAttr("a", "href"),
+				Description: description,
+				Date:        parseAge(age, time.Now()),
 			}
 		},
 		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
-			return Url("search.naver" + e.Attr("href"), options.Lang)
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("offset", strconv.Itoa(page-1))
+			url.RawQuery = qry.Encode()
+			return url.String()
 		},
-		resultSelector:     ".paging a.next",
-		paginationSelector: "a.pagination-next",
+		prepareRequest: func(r *colly.Request, options *SearchOptions) {
+			// brave only takes its settings from cookies
+			cookies := []string{"safesearch=moderate"}
+			if options.SafeSearch {
+				cookies[0] = "safesearch=strict"
+			}
+			if options.Lang != "" {
+				lang := strings.ToLower(strings.Replace(options.Lang, "_", "-", 1))
+				cookies = append(cookies, "ui_lang="+lang)
+				if i := strings.Index(lang, "-"); i >= 0 {
+					cookies = append(cookies, "country="+lang[i+1:])
+				}
+			}
+			r.Headers.Set("Cookie", strings.Join(cookies, "; "))
+		},
+		resultSelector:     "#results .snippet[data-type='web']",
+		paginationSelector: "#pagination a.button:last-child:not(.disabled)",
 	}
-}
\ No newline at end of file
+}
+
+func Mojeek() SearchEngine {
+	Url := func(path string, lang string) string {
+		lang = strings.ToLower(strings.Replace(lang, "_", "-", 1))
+		params := url.Values{}
+		if lang != "" {
+			parts := strings.SplitN(lang, "-", 2)
+			params.Set("lb", parts[0])
+			if len(parts) == 2 {
+				params.Set("arc", parts[1])
+			}
+		}
+		return getUrl("https://www.mojeek.com", path, params)
+	}
+	caps := Capabilities{
+		// since only takes a date, so the last hour can't be searched
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "year",
+		},
+		DateRange:  true,
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "mojeek",
+			Short:        "M",
+			DisplayName:  "Mojeek",
+			Homepage:     "https://www.mojeek.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:   true,
+			firefox:  true,
+			chromeM:  true,
+			firefoxM: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("q", query)
+			since, before := searchPeriod(&caps, options)
+			if !since.IsZero() {
+				params.Set("since", since.Format("20060102"))
+			}
+			if !before.IsZero() {
+				params.Set("before", before.Format("20060102"))
+			}
+			if options.SafeSearch {
+				params.Set("safe", "1")
+			}
+			return Url("search?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			return Result{
+				Title:       e.ChildText("h2 a"),
+				Link:        e.ChildAttr("h2 a", "href"),
+				Description: e.ChildText("p.s"),
+				Date:        parseAge(e.ChildText(".mdate"), time.Now()),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("s", strconv.Itoa((page-1)*10+1))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		resultSelector:     "ul.results-standard > li",
+		paginationSelector: ".pagination a[title='Next page']",
+	}
+}
+
+// yandexRegions maps countries to the lr region ids yandex uses for them
+var yandexRegions = map[string]string{
+	"by": "149",
+	"de": "96",
+	"gb": "102",
+	"kz": "159",
+	"ru": "225",
+	"tr": "983",
+	"ua": "187",
+	"us": "84",
+}
+
+func Yandex() SearchEngine {
+	Url := func(path string, lang string) string {
+		lang = strings.ToLower(strings.Replace(lang, "_", "-", 1))
+		parts := strings.SplitN(lang, "-", 2)
+		params := url.Values{"lang": {parts[0]}}
+		if len(parts) == 2 {
+			params.Set("lr", yandexRegions[parts[1]])
+		}
+		return getUrl("https://yandex.com", path, params)
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "month",
+			"month": "month",
+		},
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationPage,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "yandex",
+			Short:        "X",
+			DisplayName:  "Yandex",
+			Homepage:     "https://yandex.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("text", query)
+			switch caps.timerange(options) {
+			case "day":
+				params.Set("within", "77")
+			case "month":
+				params.Set("within", "2")
+			}
+			if options.SafeSearch {
+				params.Set("family", "yes")
+			}
+			return Url("search/?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			return Result{
+				Title:       e.ChildText("h2"),
+				Link:        e.ChildAttr("a.OrganicTitle-Link, h2 a", "href"),
+				Description: e.ChildText(".OrganicTextContentSpan, .text-container"),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			// yandex counts pages from 0
+			qry.Set("p", strconv.Itoa(page-1))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		Blocked: func(r *colly.Response) bool {
+			return strings.HasPrefix(r.Request.URL.Path, "/showcaptcha") || strings.HasPrefix(r.Request.URL.Path, "/checkcaptcha")
+		},
+		resultSelector:     "#search-result > li.serp-item",
+		paginationSelector: ".Pager-Item_type_next, a.pager__item_kind_next",
+	}
+}
+
+func Baidu() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://www.baidu.com", path, nil)
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		DateRange:  true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "baidu",
+			Short:        "U",
+			DisplayName:  "Baidu",
+			Homepage:     "https://www.baidu.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("wd", query)
+			params.Set("rn", "10")
+			now := time.Now()
+			from, to := searchPeriod(&caps, options)
+			if options.From != nil || options.To != nil {
+				if from.IsZero() {
+					from = time.Unix(0, 0)
+				}
+				if to.IsZero() {
+					to = now
+				}
+				params.Set("gpc", fmt.Sprintf("stf=%d,%d|stftype=2", from.Unix(), to.Unix()))
+			} else if !from.IsZero() {
+				params.Set("gpc", fmt.Sprintf("stf=%d,%d|stftype=1", from.Unix(), now.Unix()))
+			}
+			return Url("s?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			// mu holds the actual target of the redirect link, if present
+			link := e.Attr("mu")
+			if link == "" {
+				link = e.ChildAttr("h3 a", "href")
+			}
+			return Result{
+				Title:       e.ChildText("h3"),
+				Link:        link,
+				Description: e.ChildText(".c-abstract, [class*='content-right']"),
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("pn", strconv.Itoa((page-1)*10))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		Blocked: func(r *colly.Response) bool {
+			return r.Request.URL.Host == "wappass.baidu.com" || bytes.Contains(r.Body, []byte("<title>百度安全验证</title>"))
+		},
+		DecodeLink:         opaqueRedirectDecoder("/link"),
+		resultSelector:     "#content_left > div.c-container",
+		paginationSelector: "#page a.n:contains('下一页')",
+		charsets:           []string{"GBK", "GB-18030"},
+	}
+}
@@ -0,0 +1,60 @@
+package engines
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+func TestCrawlClosesConnections(t *testing.T) {
+	var lock sync.Mutex
+	open := make(map[net.Conn]bool)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch state {
+		case http.StateNew:
+			open[conn] = true
+		case http.StateClosed, http.StateHijacked:
+			delete(open, conn)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+	en := SearchEngine{
+		Info: Info{Name: "test"},
+		SearchUrl: func(query string, options *SearchOptions) string {
+			return srv.URL
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			return []Result{{Title: "result", Link: "https://example.org/"}}, "", nil
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := en.Crawl("golang", &SearchOptions{Pages: 1, UserAgent: "googly"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		lock.Lock()
+		n := len(open)
+		lock.Unlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d connections are still open after searching", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,113 @@
+package engines
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gocolly/colly"
+)
+
+// RateLimitedError is returned when a search engine refuses to answer because
+// too many requests were made
+type RateLimitedError struct {
+	URL string
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited while requesting %s", e.URL)
+}
+
+// BlockedError is returned when a search engine answers with a captcha or
+// otherwise blocks automated requests
+type BlockedError struct {
+	URL string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("blocked by captcha while requesting %s", e.URL)
+}
+
+// StatusError is returned when a search engine answers with an unexpected HTTP status
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d (%s) while requesting %s", e.StatusCode, http.StatusText(e.StatusCode), e.URL)
+}
+
+// NetworkError is returned when a request could not be completed at all
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("request to %s failed: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// ParseError is returned when a search engine response could not be parsed
+type ParseError struct {
+	URL string
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("could not parse response from %s: %v", e.URL, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// APIError is returned when a search engine API reports an error of its own
+type APIError struct {
+	URL     string
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("error %s (%s) while requesting %s", e.Code, e.Message, e.URL)
+}
+
+var captchaMarkers = [][]byte{
+	[]byte("captcha"),
+	[]byte("unusual traffic"),
+}
+
+func looksLikeCaptcha(body []byte) bool {
+	body = bytes.ToLower(body)
+	for _, marker := range captchaMarkers {
+		if bytes.Contains(body, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseError turns a failed colly response into one of the typed errors above
+func responseError(r *colly.Response, err error) error {
+	url := r.Request.URL.String()
+	switch {
+	case r.StatusCode == 0:
+		return &NetworkError{URL: url, Err: err}
+	case r.StatusCode == http.StatusTooManyRequests && looksLikeCaptcha(r.Body):
+		return &BlockedError{URL: url}
+	case r.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitedError{URL: url}
+	case (r.StatusCode == http.StatusForbidden || r.StatusCode == http.StatusServiceUnavailable) && looksLikeCaptcha(r.Body):
+		return &BlockedError{URL: url}
+	case r.StatusCode >= 203:
+		// colly treats every status from 203 upwards as an error
+		return &StatusError{URL: url, StatusCode: r.StatusCode}
+	default:
+		return &ParseError{URL: url, Err: err}
+	}
+}
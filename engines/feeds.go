@@ -0,0 +1,97 @@
+package engines
+
+import (
+	"bytes"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// feedDateLayouts lists the layouts used for dates in RSS and Atom feeds
+var feedDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+}
+
+// parseFeed extracts the items of an RSS or the entries of an Atom feed
+func parseFeed(body []byte) ([]Result, error) {
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, item := range xmlquery.Find(doc, "//item") {
+		results = append(results, Result{
+			Title:       childText(item, "title"),
+			Link:        childText(item, "link"),
+			Description: stripTags(childText(item, "description")),
+			Date:        feedDate(childText(item, "pubDate")),
+		})
+	}
+	for _, entry := range xmlquery.Find(doc, "//entry") {
+		description := childText(entry, "summary")
+		if description == "" {
+			description = childText(entry, "content")
+		}
+		date := childText(entry, "published")
+		if date == "" {
+			date = childText(entry, "updated")
+		}
+		results = append(results, Result{
+			Title:       childText(entry, "title"),
+			Link:        atomLink(entry, "alternate"),
+			Description: stripTags(description),
+			Date:        feedDate(date),
+		})
+	}
+	return results, nil
+}
+
+// atomLink returns the href of the link of an Atom entry with the given
+// rel, links without a rel count as alternate links
+func atomLink(entry *xmlquery.Node, rel string) string {
+	for _, link := range xmlquery.Find(entry, "link") {
+		linkRel := link.SelectAttr("rel")
+		if linkRel == rel || (linkRel == "" && rel == "alternate") {
+			return link.SelectAttr("href")
+		}
+	}
+	return ""
+}
+
+func childText(node *xmlquery.Node, name string) string {
+	if child := xmlquery.FindOne(node, name); child != nil {
+		return strings.TrimSpace(child.InnerText())
+	}
+	return ""
+}
+
+// localChild returns the first child element of node with the given name,
+// whatever namespace it is in
+func localChild(node *xmlquery.Node, name string) *xmlquery.Node {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xmlquery.ElementNode && child.Data == name {
+			return child
+		}
+	}
+	return nil
+}
+
+func feedDate(str string) *time.Time {
+	for _, layout := range feedDateLayouts {
+		if date, err := time.Parse(layout, str); err == nil {
+			return &date
+		}
+	}
+	return nil
+}
+
+// stripTags turns an html snippet into plain text
+func stripTags(snippet string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(snippet, "")))
+}
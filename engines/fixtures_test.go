@@ -0,0 +1,104 @@
+package engines
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves recorded responses from testdata in order, repeating
+// the last one, and records the requests it receives
+type fixtureServer struct {
+	*httptest.Server
+	// status is the status code of the responses, 200 if zero
+	status   int
+	lock     sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newFixtureServer(t *testing.T, contentType string, files ...string) *fixtureServer {
+	var pages [][]byte
+	for _, file := range files {
+		page, err := ioutil.ReadFile(filepath.Join("testdata", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, page)
+	}
+	srv := &fixtureServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		srv.lock.Lock()
+		i := len(srv.requests)
+		srv.requests = append(srv.requests, r)
+		srv.bodies = append(srv.bodies, body)
+		srv.lock.Unlock()
+		if i >= len(pages) {
+			i = len(pages) - 1
+		}
+		w.Header().Set("Content-Type", contentType)
+		if srv.status != 0 {
+			w.WriteHeader(srv.status)
+		}
+		w.Write(pages[i])
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// query returns the query parameters of the i-th request
+func (srv *fixtureServer) query(i int) url.Values {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+	return srv.requests[i].URL.Query()
+}
+
+// redirect makes en send its first request to srv instead of its own host
+func (srv *fixtureServer) redirect(en *SearchEngine) {
+	searchUrl := en.SearchUrl
+	target, _ := url.Parse(srv.URL)
+	en.SearchUrl = func(query string, options *SearchOptions) string {
+		u, _ := url.Parse(searchUrl(query, options))
+		u.Scheme, u.Host = target.Scheme, target.Host
+		return u.String()
+	}
+}
+
+// parseQuery returns the query parameters of link
+func parseQuery(t *testing.T, link string) url.Values {
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid url %s: %v", link, err)
+	}
+	return u.Query()
+}
+
+// assertResults compares the titles, links and descriptions of results
+func assertResults(t *testing.T, results []Result, want []Result) {
+	t.Helper()
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, result := range results {
+		if result.Title != want[i].Title {
+			t.Errorf("result %d: title = %q, want %q", i, result.Title, want[i].Title)
+		}
+		if result.Link != want[i].Link {
+			t.Errorf("result %d: link = %q, want %q", i, result.Link, want[i].Link)
+		}
+		if result.Description != want[i].Description {
+			t.Errorf("result %d: description = %q, want %q", i, result.Description, want[i].Description)
+		}
+	}
+}
+
+func dateOf(year int, month time.Month, day int) *time.Time {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
@@ -0,0 +1,101 @@
+package engines
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// GoDevExtra holds the details pkg.go.dev shows about a package
+type GoDevExtra struct {
+	// Path is the import path of the package, which is also the module
+	// path for the root package of a module
+	Path       string
+	Version    string
+	ImportedBy int
+	License    string
+	Synopsis   string
+	// Symbol is the matching symbol in symbol search mode
+	Symbol string `json:",omitempty" xml:",omitempty"`
+}
+
+// goDevSymbolPrefix switches to symbol search when a query starts with it
+const goDevSymbolPrefix = "symbol:"
+
+func GoDev() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://pkg.go.dev", path, nil)
+	}
+	caps := Capabilities{
+		Pagination: PaginationPage,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "godev",
+			Short:        "GO",
+			DisplayName:  "pkg.go.dev",
+			Homepage:     "https://pkg.go.dev",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			if strings.HasPrefix(query, goDevSymbolPrefix) {
+				query = strings.TrimSpace(strings.TrimPrefix(query, goDevSymbolPrefix))
+				params.Set("m", "symbol")
+			} else {
+				params.Set("m", "package")
+			}
+			params.Set("q", query)
+			return Url("search?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			path := strings.Trim(e.ChildText(".SearchSnippet-header-path"), "()")
+			extra := GoDevExtra{
+				Path:     path,
+				License:  e.ChildText("[data-test-id='snippet-license']"),
+				Synopsis: e.ChildText(".SearchSnippet-synopsis"),
+				Symbol:   e.ChildText(".SearchSnippet-symbolCode"),
+			}
+			importedBy := strings.Replace(e.ChildText(".SearchSnippet-infoLabel a[href$='tab=importedby'] strong"), ",", "", -1)
+			extra.ImportedBy, _ = strconv.Atoi(importedBy)
+			e.ForEach(".SearchSnippet-infoLabel > span", func(_ int, span *colly.HTMLElement) {
+				if strings.Contains(span.Text, "published on") {
+					extra.Version = strings.TrimSpace(span.DOM.ChildrenFiltered("strong").First().Text())
+				}
+			})
+			// AbsoluteURL drops the fragment, which selects the symbol
+			link := e.ChildAttr(".SearchSnippet-headerContainer h2 a", "href")
+			if ref, err := url.Parse(link); err == nil {
+				link = e.Request.URL.ResolveReference(ref).String()
+			}
+			var date *time.Time
+			if published, err := time.Parse("Jan 2, 2006", e.ChildText("[data-test-id='snippet-published']")); err == nil {
+				date = &published
+			}
+			return Result{
+				Title:       strings.Join(strings.Fields(e.ChildText(".SearchSnippet-headerContainer h2 a")), " "),
+				Link:        link,
+				Description: extra.Synopsis,
+				Date:        date,
+				Extra:       extra,
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("page", strconv.Itoa(page))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		resultSelector:     ".SearchSnippet",
+		paginationSelector: "a.Pagination-next[href]",
+	}
+}
@@ -0,0 +1,120 @@
+package engines
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoDev(t *testing.T) {
+	srv := newFixtureServer(t, "text/html; charset=utf-8", "godev.html", "godev_2.html")
+	en := GoDev()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("mux", &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "mux (github.com/gorilla/mux)",
+			Link:        srv.URL + "/github.com/gorilla/mux",
+			Description: "Package mux implements a request router and dispatcher.",
+		},
+		{
+			Title:       "cmux (github.com/soheilhy/cmux)",
+			Link:        srv.URL + "/github.com/soheilhy/cmux",
+			Description: "Package cmux is a library to multiplex network connections based on their payload.",
+		},
+		{
+			Title:       "otelmux (github.com/gorilla/mux/otelmux)",
+			Link:        srv.URL + "/github.com/gorilla/mux/otelmux",
+			Description: "Package otelmux instruments the gorilla/mux package.",
+		},
+	})
+	extras := []GoDevExtra{
+		{
+			Path:       "github.com/gorilla/mux",
+			Version:    "v1.8.1",
+			ImportedBy: 21438,
+			License:    "BSD-3-Clause",
+			Synopsis:   "Package mux implements a request router and dispatcher.",
+		},
+		{
+			Path:       "github.com/soheilhy/cmux",
+			Version:    "v0.1.5",
+			ImportedBy: 612,
+			License:    "Apache-2.0",
+			Synopsis:   "Package cmux is a library to multiplex network connections based on their payload.",
+		},
+		{
+			Path:       "github.com/gorilla/mux/otelmux",
+			Version:    "v0.49.0",
+			ImportedBy: 0,
+			License:    "Apache-2.0",
+			Synopsis:   "Package otelmux instruments the gorilla/mux package.",
+		},
+	}
+	dates := []time.Time{
+		time.Date(2023, 10, 18, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 23, 0, 0, 0, 0, time.UTC),
+	}
+	for i, extra := range extras {
+		if !reflect.DeepEqual(results[i].Extra, extra) {
+			t.Errorf("result %d: extra = %+v, want %+v", i, results[i].Extra, extra)
+		}
+		if results[i].Date == nil || !results[i].Date.Equal(dates[i]) {
+			t.Errorf("result %d: date = %v, want %v", i, results[i].Date, dates[i])
+		}
+	}
+
+	// the next link on the second page is disabled
+	if len(srv.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(srv.requests))
+	}
+	for i, page := range []string{"", "2"} {
+		qry := srv.query(i)
+		if got := qry.Get("page"); got != page {
+			t.Errorf("request %d: page = %q, want %q", i, got, page)
+		}
+		if got := qry.Get("m"); got != "package" {
+			t.Errorf("request %d: m = %q", i, got)
+		}
+		if got := qry.Get("q"); got != "mux" {
+			t.Errorf("request %d: q = %q", i, got)
+		}
+	}
+}
+
+func TestGoDevSymbols(t *testing.T) {
+	srv := newFixtureServer(t, "text/html; charset=utf-8", "godev_symbol.html")
+	en := GoDev()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("symbol: NewRouter", &SearchOptions{Pages: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{{
+		Title:       "NewRouter (github.com/gorilla/mux)",
+		Link:        srv.URL + "/github.com/gorilla/mux#NewRouter",
+		Description: "NewRouter returns a new router instance.",
+	}})
+	want := GoDevExtra{
+		Path:       "github.com/gorilla/mux",
+		Version:    "v1.8.1",
+		ImportedBy: 21438,
+		License:    "BSD-3-Clause",
+		Synopsis:   "NewRouter returns a new router instance.",
+		Symbol:     "func NewRouter() *Router",
+	}
+	if !reflect.DeepEqual(results[0].Extra, want) {
+		t.Errorf("extra = %+v, want %+v", results[0].Extra, want)
+	}
+
+	qry := srv.query(0)
+	if qry.Get("m") != "symbol" || qry.Get("q") != "NewRouter" {
+		t.Errorf("got m = %q, q = %q, want a symbol search for NewRouter", qry.Get("m"), qry.Get("q"))
+	}
+}
@@ -0,0 +1,128 @@
+package engines
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// HackerNewsExtra holds the details Hacker News reports about a story
+type HackerNewsExtra struct {
+	Points   int
+	Comments int
+	Author   string
+	// Discussion links to the comments on Hacker News
+	Discussion string
+}
+
+// hackerNewsResponse is the part of an Algolia search response googly uses
+type hackerNewsResponse struct {
+	Message string
+	Hits    []struct {
+		ObjectID    string
+		Title       string
+		Url         string
+		Author      string
+		Points      int
+		NumComments int    `json:"num_comments"`
+		CreatedAtI  int64  `json:"created_at_i"`
+		StoryText   string `json:"story_text"`
+	}
+	Page    int
+	NbPages int
+}
+
+const hackerNewsPageSize = 20
+
+func HackerNews() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://hn.algolia.com/api/v1/", path, nil)
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		DateRange:  true,
+		Sort:       true,
+		Pagination: PaginationPage,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "hackernews",
+			Short:        "HN",
+			DisplayName:  "Hacker News",
+			Homepage:     "https://news.ycombinator.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("query", query)
+			params.Set("tags", "story")
+			params.Set("page", "0")
+			params.Set("hitsPerPage", strconv.Itoa(hackerNewsPageSize))
+			from, to := searchPeriod(&caps, options)
+			var filters []string
+			if !from.IsZero() {
+				filters = append(filters, fmt.Sprintf("created_at_i>=%d", from.Unix()))
+			}
+			if !to.IsZero() {
+				filters = append(filters, fmt.Sprintf("created_at_i<=%d", to.Unix()))
+			}
+			if len(filters) > 0 {
+				params.Set("numericFilters", strings.Join(filters, ","))
+			}
+			endpoint := "search"
+			if options.Sort == SortDate {
+				endpoint = "search_by_date"
+			}
+			return Url(endpoint+"?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res hackerNewsResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+			if res.Message != "" {
+				return nil, "", &APIError{URL: r.Request.URL.String(), Code: strconv.Itoa(r.StatusCode), Message: res.Message}
+			}
+
+			var results []Result
+			for _, hit := range res.Hits {
+				discussion := "https://news.ycombinator.com/item?id=" + hit.ObjectID
+				link := hit.Url
+				if link == "" {
+					link = discussion
+				}
+				date := time.Unix(hit.CreatedAtI, 0).UTC()
+				results = append(results, Result{
+					Title:       hit.Title,
+					Link:        link,
+					Description: stripTags(hit.StoryText),
+					Date:        &date,
+					Extra: HackerNewsExtra{
+						Points:     hit.Points,
+						Comments:   hit.NumComments,
+						Author:     hit.Author,
+						Discussion: discussion,
+					},
+				})
+			}
+			if res.Page+1 >= res.NbPages {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("page", strconv.Itoa(res.Page+1))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
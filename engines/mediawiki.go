@@ -0,0 +1,171 @@
+package engines
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// MediaWikiExtra holds the details MediaWiki reports about an article
+type MediaWikiExtra struct {
+	PageID    int
+	WordCount int
+	// Size is the size of the article in bytes
+	Size int
+	// LastEdited is when the article was last edited
+	LastEdited time.Time
+}
+
+// mediaWikiResponse is the part of a list=search response googly uses
+type mediaWikiResponse struct {
+	Error *struct {
+		Code string
+		Info string
+	}
+	Continue *struct {
+		Sroffset int
+	}
+	Query struct {
+		Search []struct {
+			Title     string
+			PageID    int
+			Size      int
+			WordCount int
+			Snippet   string
+			Timestamp string
+		}
+	}
+}
+
+const mediaWikiPageSize = 10
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Wikipedia searches the wikipedia edition in the search language
+func Wikipedia() SearchEngine {
+	host := func(lang string) string {
+		lang = strings.ToLower(strings.SplitN(strings.Replace(lang, "_", "-", 1), "-", 2)[0])
+		if lang == "" {
+			lang = "en"
+		}
+		return "https://" + lang + ".wikipedia.org"
+	}
+	en := mediaWiki(
+		func(lang string) string {
+			return host(lang) + "/w/api.php"
+		},
+		func(lang string, title string) string {
+			return host(lang) + "/wiki/" + url.PathEscape(strings.Replace(title, " ", "_", -1))
+		},
+	)
+	en.Info = Info{
+		Name:         "wikipedia",
+		Short:        "W",
+		DisplayName:  "Wikipedia",
+		Homepage:     "https://www.wikipedia.org",
+		Capabilities: en.Info.Capabilities,
+	}
+	en.Info.Capabilities.Languages = true
+	return en
+}
+
+// MediaWiki searches the MediaWiki instance whose api.php is at api, e.g.
+// http://localhost/w/api.php
+func MediaWiki(api string) SearchEngine {
+	base := strings.TrimSuffix(api, "api.php")
+	en := mediaWiki(
+		func(lang string) string {
+			return api
+		},
+		func(lang string, title string) string {
+			return base + "index.php?title=" + url.QueryEscape(strings.Replace(title, " ", "_", -1))
+		},
+	)
+	en.Info = Info{
+		Name:         "mediawiki",
+		Short:        "W",
+		DisplayName:  "MediaWiki",
+		Homepage:     base,
+		Capabilities: en.Info.Capabilities,
+	}
+	return en
+}
+
+// mediaWiki creates an engine using the api.php returned by api, linking to
+// articles using article
+func mediaWiki(api func(lang string) string, article func(lang string, title string) string) SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl(api(lang), path, nil)
+	}
+	caps := Capabilities{
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("action", "query")
+			params.Set("list", "search")
+			params.Set("format", "json")
+			params.Set("srsearch", query)
+			params.Set("srlimit", strconv.Itoa(mediaWikiPageSize))
+			params.Set("srprop", "snippet|wordcount|size|timestamp")
+			return Url("?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res mediaWikiResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+			if res.Error != nil {
+				link := r.Request.URL.String()
+				if res.Error.Code == "ratelimited" {
+					return nil, "", &RateLimitedError{URL: link}
+				}
+				return nil, "", &APIError{URL: link, Code: res.Error.Code, Message: res.Error.Info}
+			}
+
+			var results []Result
+			for _, item := range res.Query.Search {
+				extra := MediaWikiExtra{
+					PageID:    item.PageID,
+					WordCount: item.WordCount,
+					Size:      item.Size,
+				}
+				result := Result{
+					Title:       item.Title,
+					Link:        article(options.Lang, item.Title),
+					Description: stripTags(item.Snippet),
+				}
+				// MediaWiki doesn't report when articles were created, so
+				// the last edit is the only date there is
+				if date, err := time.Parse(time.RFC3339, item.Timestamp); err == nil {
+					extra.LastEdited = date
+					result.Date = &date
+				}
+				result.Extra = extra
+				results = append(results, result)
+			}
+			if res.Continue == nil {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("sroffset", strconv.Itoa(res.Continue.Sroffset))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package engines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMediaWiki(t *testing.T) {
+	srv := newFixtureServer(t, "application/json; charset=utf-8", "mediawiki.json")
+	en := MediaWiki(srv.URL + "/w/api.php")
+
+	results, err := en.Crawl("go", &SearchOptions{Pages: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "Go (programming language)",
+			Link:        srv.URL + "/w/index.php?title=Go_%28programming_language%29",
+			Description: "Go is a statically typed, compiled high-level programming language",
+		},
+		{
+			Title:       "Gopher",
+			Link:        srv.URL + "/w/index.php?title=Gopher",
+			Description: "The Go mascot",
+		},
+	})
+
+	edited := time.Date(2024, 3, 12, 10, 15, 30, 0, time.UTC)
+	extra := results[0].Extra.(MediaWikiExtra)
+	if extra.PageID != 25039021 || extra.WordCount != 6480 || extra.Size != 73812 || !extra.LastEdited.Equal(edited) {
+		t.Errorf("got %+v", extra)
+	}
+	if results[0].Date == nil || !results[0].Date.Equal(edited) {
+		t.Errorf("date = %v, want the last edit", results[0].Date)
+	}
+	if extra := results[1].Extra.(MediaWikiExtra); !extra.LastEdited.IsZero() || results[1].Date != nil {
+		t.Errorf("got %+v and date %v for an invalid timestamp", extra, results[1].Date)
+	}
+}
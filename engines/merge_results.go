@@ -1,15 +1,26 @@
 package engines
 
-func unique(slice []Result) []Result {
-    keys := make(map[string]bool)
-    list := []Result{} 
-    for _, entry := range slice {
-        if _, value := keys[entry.Link]; !value {
-            keys[entry.Link] = true
-            list = append(list, entry)
-        }
-    }    
-    return list
+import "sort"
+
+// unique removes results whose links are equal under rules, the sources of
+// removed duplicates are added to the result that is kept
+func unique(slice []Result, rules *CanonicalRules) []Result {
+	keys := make(map[string]int)
+	list := []Result{}
+	for _, entry := range slice {
+		key := rules.Canonical(entry.Link)
+		if i, ok := keys[key]; ok {
+			list[i].Sources = append(list[i].Sources, entry.Sources...)
+			if list[i].Date == nil {
+				list[i].Date = entry.Date
+			}
+			continue
+		}
+		keys[key] = len(list)
+		entry.Sources = append([]Source(nil), entry.Sources...)
+		list = append(list, entry)
+	}
+	return list
 }
 
 func merge(slices [][]Result) []Result {
@@ -29,4 +40,36 @@ func merge(slices [][]Result) []Result {
 		}
 	}
 	return merged
-}
\ No newline at end of file
+}
+
+// fusionK dampens the advantage of the very first ranks in reciprocal rank fusion
+const fusionK = 60
+
+// fuse ranks results using weighted reciprocal rank fusion. Every engine adds
+// weight / (fusionK + rank) to the score of each result it returned, so a
+// result found by many engines beats one only a single engine ranked highly.
+// Ties are broken by the round-robin order.
+func fuse(names []string, slices [][]Result, weights map[string]float64, rules *CanonicalRules) []Result {
+	scores := make(map[string]float64)
+	for i, slice := range slices {
+		weight := 1.0
+		if w, ok := weights[names[i]]; ok {
+			weight = w
+		}
+		seen := make(map[string]bool)
+		for rank, result := range slice {
+			key := rules.Canonical(result.Link)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			scores[key] += weight / float64(fusionK+rank+1)
+		}
+	}
+
+	fused := unique(merge(slices), rules)
+	sort.SliceStable(fused, func(i, j int) bool {
+		return scores[rules.Canonical(fused[i].Link)] > scores[rules.Canonical(fused[j].Link)]
+	})
+	return fused
+}
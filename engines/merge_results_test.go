@@ -0,0 +1,93 @@
+package engines
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocolly/colly"
+)
+
+// fusionEngines returns three engines which all rank x third while only
+// the first one returns y, in first place
+func fusionEngines(t *testing.T) []SearchEngine {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+	links := map[string][]string{
+		"a": {"y", "a2", "x", "a4"},
+		"b": {"b1", "b2", "x", "b4"},
+		"c": {"c1", "c2", "x", "c4"},
+	}
+	var engines []SearchEngine
+	for _, name := range []string{"a", "b", "c"} {
+		var results []Result
+		for _, link := range links[name] {
+			results = append(results, Result{Title: link, Link: "https://example.org/" + link})
+		}
+		engines = append(engines, SearchEngine{
+			Info: Info{Name: name},
+			SearchUrl: func(query string, options *SearchOptions) string {
+				return srv.URL
+			},
+			Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+				return results, "", nil
+			},
+		})
+	}
+	return engines
+}
+
+func titles(results []Result) string {
+	var titles []string
+	for _, result := range results {
+		titles = append(titles, result.Title)
+	}
+	return fmt.Sprint(titles)
+}
+
+func TestCombinedMerge(t *testing.T) {
+	tests := []struct {
+		options SearchOptions
+		order   string
+	}{
+		// x is ranked third by every engine and beats y, which only a ranked first
+		{SearchOptions{Pages: 1, UserAgent: "googly"}, "[x y b1 c1 a2 b2 c2 a4 b4 c4]"},
+		{SearchOptions{Pages: 1, UserAgent: "googly", Merge: MergeFusion}, "[x y b1 c1 a2 b2 c2 a4 b4 c4]"},
+		{SearchOptions{Pages: 1, UserAgent: "googly", Weights: map[string]float64{"b": 0, "c": 0}}, "[y a2 x a4 b1 c1 b2 c2 b4 c4]"},
+		{SearchOptions{Pages: 1, UserAgent: "googly", Weights: map[string]float64{"b": 2}}, "[x b1 b2 b4 y c1 a2 c2 a4 c4]"},
+		// round robin keeps the order the engines returned
+		{SearchOptions{Pages: 1, UserAgent: "googly", Merge: MergeRoundRobin}, "[y b1 c1 a2 b2 c2 x a4 b4 c4]"},
+	}
+	for _, test := range tests {
+		results, statuses, err := Combined("golang", &test.options, fusionEngines(t)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := titles(results); got != test.order {
+			t.Errorf("merge %q, weights %v: got %s, want %s", test.options.Merge, test.options.Weights, got, test.order)
+		}
+		if len(statuses) != 3 {
+			t.Errorf("got %d statuses", len(statuses))
+		}
+	}
+}
+
+func TestFuseSources(t *testing.T) {
+	engines := fusionEngines(t)
+	results, _, err := Combined("golang", &SearchOptions{Pages: 1, UserAgent: "googly"}, engines...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := results[0]
+	if len(x.Sources) != 3 {
+		t.Fatalf("got sources %+v, want all three engines", x.Sources)
+	}
+	for i, source := range x.Sources {
+		if source.Engine != engines[i].Info.Name || source.Rank != 3 {
+			t.Errorf("source %d: got %+v", i, source)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package engines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMojeek(t *testing.T) {
+	srv := newFixtureServer(t, "text/html; charset=utf-8", "mojeek.html", "mojeek_2.html")
+	en := Mojeek()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "The Go Programming Language",
+			Link:        "https://go.dev/",
+			Description: "Go is an open source programming language that makes it simple to build secure, scalable systems.",
+		},
+		{
+			Title:       "Go by Example",
+			Link:        "https://gobyexample.com/",
+			Description: "Go by Example is a hands-on introduction to Go using annotated example programs.",
+		},
+		{
+			Title:       "A Tour of Go",
+			Link:        "https://go.dev/tour/",
+			Description: "Welcome to a tour of the Go programming language.",
+		},
+	})
+	dates := []*time.Time{
+		dateOf(2024, 3, 12),
+		nil,
+		dateOf(2023, 1, 5),
+	}
+	for i, date := range dates {
+		got := results[i].Date
+		if (got == nil) != (date == nil) || got != nil && !got.Equal(*date) {
+			t.Errorf("result %d: date = %v, want %v", i, got, date)
+		}
+	}
+
+	if len(srv.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(srv.requests))
+	}
+	if got := srv.query(0).Get("s"); got != "" {
+		t.Errorf("s = %q on page 1", got)
+	}
+	if got := srv.query(1).Get("s"); got != "11" {
+		t.Errorf("s = %q on page 2, want 11", got)
+	}
+}
+
+func TestMojeekParams(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	now := time.Now()
+	tests := []struct {
+		options SearchOptions
+		params  map[string]string
+	}{
+		{SearchOptions{}, map[string]string{"lb": "", "arc": "", "since": "", "before": "", "safe": ""}},
+		{SearchOptions{Lang: "de"}, map[string]string{"lb": "de", "arc": ""}},
+		{SearchOptions{Lang: "en_GB"}, map[string]string{"lb": "en", "arc": "gb"}},
+		{SearchOptions{Lang: "pt-BR"}, map[string]string{"lb": "pt", "arc": "br"}},
+		{SearchOptions{SafeSearch: true}, map[string]string{"safe": "1"}},
+		{SearchOptions{Timerange: "hour"}, map[string]string{"since": now.AddDate(0, 0, -1).Format("20060102")}},
+		{SearchOptions{Timerange: "day"}, map[string]string{"since": now.AddDate(0, 0, -1).Format("20060102")}},
+		{SearchOptions{Timerange: "week"}, map[string]string{"since": now.AddDate(0, 0, -7).Format("20060102")}},
+		{SearchOptions{Timerange: "month"}, map[string]string{"since": now.AddDate(0, -1, 0).Format("20060102")}},
+		{SearchOptions{Timerange: "year"}, map[string]string{"since": now.AddDate(-1, 0, 0).Format("20060102"), "before": ""}},
+		{SearchOptions{From: &from, To: &to}, map[string]string{"since": "20240102", "before": "20240304"}},
+		{SearchOptions{From: &from}, map[string]string{"since": "20240102", "before": ""}},
+		{SearchOptions{To: &to}, map[string]string{"since": "", "before": "20240304"}},
+		// a date range takes precedence over a time range
+		{SearchOptions{Timerange: "week", To: &to}, map[string]string{"since": "", "before": "20240304"}},
+	}
+	en := Mojeek()
+	for _, test := range tests {
+		qry := parseQuery(t, en.SearchUrl("golang", &test.options))
+		if got := qry.Get("q"); got != "golang" {
+			t.Errorf("%+v: q = %q", test.options, got)
+		}
+		for param, want := range test.params {
+			if got := qry.Get(param); got != want {
+				t.Errorf("%+v: %s = %q, want %q", test.options, param, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package engines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNaver(t *testing.T) {
+	srv := newFixtureServer(t, "text/html; charset=UTF-8", "naver.html", "naver_2.html")
+	en := Naver()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{
+		{
+			Title:       "Go 프로그래밍 언어",
+			Link:        "https://go.dev/",
+			Description: "Go는 간단하고 안정적이며 효율적인 소프트웨어를 쉽게 만들 수 있는 오픈 소스 프로그래밍 언어입니다.",
+		},
+		{
+			Title:       "Go (프로그래밍 언어) - 위키백과",
+			Link:        "https://ko.wikipedia.org/wiki/Go_(%ED%94%84%EB%A1%9C%EA%B7%B8%EB%9E%98%EB%B0%8D_%EC%96%B8%EC%96%B4)",
+			Description: "Go는 2009년 구글에서 개발한 프로그래밍 언어이다.",
+		},
+		{
+			Title:       "Go Packages",
+			Link:        "https://pkg.go.dev/",
+			Description: "Go 패키지 문서를 검색합니다.",
+		},
+	}
+	assertResults(t, results, want)
+
+	// the second page has a disabled next button
+	if len(srv.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(srv.requests))
+	}
+	if got := srv.query(0).Get("query"); got != "golang" {
+		t.Errorf("query = %q", got)
+	}
+	if got := srv.query(1).Get("start"); got != "11" {
+		t.Errorf("start = %q on page 2, want 11", got)
+	}
+}
+
+func TestNaverEUCKR(t *testing.T) {
+	srv := newFixtureServer(t, "text/html", "naver_euckr.html")
+	en := Naver()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("골랭", &SearchOptions{Pages: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "골랭 한국 사용자 모임",
+			Link:        "https://golang.kr/",
+			Description: "고 언어를 사용하는 한국 개발자들의 커뮤니티입니다. 세미나와 스터디 소식을 전합니다.",
+		},
+		{
+			Title:       "고 언어 입문 강좌",
+			Link:        "https://blog.example.kr/go",
+			Description: "변수, 함수, 고루틴과 채널까지 차근차근 배워 봅시다.",
+		},
+	})
+}
+
+func TestNaverPeriod(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	today := time.Now().Format("20060102")
+	tests := []struct {
+		options SearchOptions
+		nso     string
+	}{
+		{SearchOptions{}, ""},
+		{SearchOptions{Timerange: "hour"}, "so:r,p:1h,a:all"},
+		{SearchOptions{Timerange: "day"}, "so:r,p:1d,a:all"},
+		{SearchOptions{Timerange: "week"}, "so:r,p:1w,a:all"},
+		{SearchOptions{Timerange: "month"}, "so:r,p:1m,a:all"},
+		{SearchOptions{Timerange: "year"}, "so:r,p:1y,a:all"},
+		{SearchOptions{From: &from, To: &to}, "so:r,p:from20240102to20240304,a:all"},
+		{SearchOptions{From: &from}, "so:r,p:from20240102to" + today + ",a:all"},
+		{SearchOptions{To: &to}, "so:r,p:from19900101to20240304,a:all"},
+	}
+	en := Naver()
+	for _, test := range tests {
+		qry := parseQuery(t, en.SearchUrl("golang", &test.options))
+		if got := qry.Get("nso"); got != test.nso {
+			t.Errorf("%+v: nso = %q, want %q", test.options, got, test.nso)
+		}
+	}
+}
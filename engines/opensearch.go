@@ -0,0 +1,249 @@
+package engines
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/xmlquery"
+	"github.com/gocolly/colly"
+)
+
+// openSearchTypes lists the result types googly understands, in order of preference
+var openSearchTypes = []string{
+	"application/rss+xml",
+	"application/atom+xml",
+	"text/html",
+}
+
+// openSearchResultSelectors are tried in order to find the results on html
+// result pages, as nothing else is known about their markup
+var openSearchResultSelectors = []string{
+	".search-result",
+	".search-results li",
+	".searchresult",
+	"li.result",
+	".result",
+	"#results li",
+	"article",
+}
+
+var templateParam = regexp.MustCompile(`\{([^}?]+)\??\}`)
+
+// openSearchDescription holds what googly uses from an OpenSearch description document
+type openSearchDescription struct {
+	template    string
+	feed        bool
+	indexOffset int
+	pageOffset  int
+	// paged reports whether the template takes a start index or page,
+	// byIndex whether it's the index
+	paged     bool
+	byIndex   bool
+	languages bool
+}
+
+const openSearchPageSize = 10
+
+// OpenSearch searches the site described by the OpenSearch description
+// document at descriptor, which is fetched before searching. RSS and Atom
+// results are preferred, html result pages are scraped using common markup.
+// Languages and pagination are only known to be supported once the document
+// has been fetched, see SearchEngine.Prepare.
+func OpenSearch(descriptor string) SearchEngine {
+	desc := &openSearchDescription{}
+	caps := Capabilities{
+		Pagination: PaginationNone,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "opensearch",
+			Short:        "O",
+			DisplayName:  "OpenSearch",
+			Homepage:     descriptor,
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		setup: func(transport http.RoundTripper, userAgent string) error {
+			if desc.template != "" {
+				return nil
+			}
+			return desc.load(descriptor, transport, userAgent)
+		},
+		capabilities: desc.capabilities,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			return desc.url(query, options, desc.indexOffset, desc.pageOffset)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var results []Result
+			var err error
+			if desc.feed {
+				results, err = parseFeed(r.Body)
+			} else {
+				results, err = scrapeResults(r)
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			for i, result := range results {
+				if link := r.Request.AbsoluteURL(result.Link); link != "" {
+					results[i].Link = link
+				}
+			}
+			if len(results) == 0 || !desc.paged {
+				return results, "", nil
+			}
+			// pages can differ in size, so the next index follows all
+			// results returned so far
+			returned, _ := r.Ctx.GetAny("opensearch.returned").(int)
+			returned += len(results)
+			r.Ctx.Put("opensearch.returned", returned)
+			next := desc.url(r.Ctx.Get("query"), options, desc.indexOffset+returned, desc.pageOffset+page)
+			return results, r.Request.AbsoluteURL(next), nil
+		},
+	}
+}
+
+// load fetches the description document and picks the url template to search with
+func (desc *openSearchDescription) load(descriptor string, transport http.RoundTripper, userAgent string) error {
+	req, err := http.NewRequest("GET", descriptor, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	res, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return &NetworkError{URL: descriptor, Err: err}
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &StatusError{URL: descriptor, StatusCode: res.StatusCode}
+	}
+	doc, err := xmlquery.Parse(res.Body)
+	if err != nil {
+		return &ParseError{URL: descriptor, Err: err}
+	}
+
+	templates := make(map[string]*xmlquery.Node)
+	for _, node := range xmlquery.Find(doc, "//OpenSearchDescription/Url") {
+		if rel := node.SelectAttr("rel"); rel != "" && rel != "results" {
+			continue
+		}
+		mediaType := strings.TrimSpace(strings.SplitN(node.SelectAttr("type"), ";", 2)[0])
+		if _, ok := templates[mediaType]; !ok {
+			templates[mediaType] = node
+		}
+	}
+	var node *xmlquery.Node
+	for _, mediaType := range openSearchTypes {
+		if node = templates[mediaType]; node != nil {
+			desc.feed = mediaType != "text/html"
+			break
+		}
+	}
+	if node == nil {
+		return &ParseError{URL: descriptor, Err: fmt.Errorf("no rss, atom or html url template found")}
+	}
+
+	desc.template = node.SelectAttr("template")
+	desc.indexOffset = 1
+	if offset, err := strconv.Atoi(node.SelectAttr("indexOffset")); err == nil {
+		desc.indexOffset = offset
+	}
+	desc.pageOffset = 1
+	if offset, err := strconv.Atoi(node.SelectAttr("pageOffset")); err == nil {
+		desc.pageOffset = offset
+	}
+	desc.paged, desc.byIndex, desc.languages = false, false, false
+	for _, param := range templateParam.FindAllStringSubmatch(desc.template, -1) {
+		switch param[1] {
+		case "startIndex":
+			desc.paged, desc.byIndex = true, true
+		case "startPage":
+			desc.paged = true
+		case "language":
+			desc.languages = true
+		}
+	}
+	return nil
+}
+
+// capabilities returns what the loaded url template supports
+func (desc *openSearchDescription) capabilities() Capabilities {
+	caps := Capabilities{
+		Languages:  desc.languages,
+		Pagination: PaginationNone,
+	}
+	if desc.byIndex {
+		caps.Pagination = PaginationOffset
+	} else if desc.paged {
+		caps.Pagination = PaginationPage
+	}
+	return caps
+}
+
+// url fills in the parameters of the template for the page of results for
+// query starting at startIndex or startPage
+func (desc *openSearchDescription) url(query string, options *SearchOptions, startIndex int, startPage int) string {
+	lang := options.Lang
+	if lang == "" {
+		lang = "*"
+	}
+	return templateParam.ReplaceAllStringFunc(desc.template, func(param string) string {
+		switch templateParam.FindStringSubmatch(param)[1] {
+		case "searchTerms":
+			return strings.Replace(url.QueryEscape(query), "+", "%20", -1)
+		case "startIndex":
+			return strconv.Itoa(startIndex)
+		case "startPage":
+			return strconv.Itoa(startPage)
+		case "count":
+			return strconv.Itoa(openSearchPageSize)
+		case "language":
+			return url.QueryEscape(lang)
+		case "inputEncoding", "outputEncoding":
+			return "UTF-8"
+		default:
+			return ""
+		}
+	})
+}
+
+// scrapeResults extracts results from an html result page by trying
+// openSearchResultSelectors until one matches
+func scrapeResults(r *colly.Response) ([]Result, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, selector := range openSearchResultSelectors {
+		doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+			link, ok := s.Find("a[href]").First().Attr("href")
+			if !ok {
+				return
+			}
+			title := strings.TrimSpace(s.Find("h1, h2, h3, h4").First().Text())
+			if title == "" {
+				title = strings.TrimSpace(s.Find("a[href]").First().Text())
+			}
+			results = append(results, Result{
+				Title:       title,
+				Link:        r.Request.AbsoluteURL(link),
+				Description: strings.TrimSpace(s.Find("p").First().Text()),
+			})
+		})
+		if len(results) > 0 {
+			break
+		}
+	}
+	return results, nil
+}
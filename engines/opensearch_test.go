@@ -0,0 +1,217 @@
+package engines
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// openSearchServer serves a description document with the given url
+// template and rss or atom result pages with two relative links each
+func openSearchServer(t *testing.T, urlType string, template string, attrs string) (*httptest.Server, func() []string) {
+	var lock sync.Mutex
+	var requests []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/opensearch.xml" {
+			w.Header().Set("Content-Type", "application/opensearchdescription+xml")
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+  <ShortName>Blog</ShortName>
+  <Url type="text/html" template="%[1]s/html?q={searchTerms}"/>
+  <Url type="%[2]s" template="%[1]s%[3]s" %[4]s/>
+</OpenSearchDescription>`, srv.URL, urlType, template, attrs)
+			return
+		}
+		lock.Lock()
+		n := len(requests)
+		requests = append(requests, r.RequestURI)
+		lock.Unlock()
+		first, second := strconv.Itoa(2*n+1), strconv.Itoa(2*n+2)
+		if urlType == "application/atom+xml" {
+			w.Header().Set("Content-Type", urlType)
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Search results</title>
+  <entry><title>Post %[1]s</title><link href="/posts/%[1]s"/><summary>Summary %[1]s</summary></entry>
+  <entry><title>Post %[2]s</title><link rel="alternate" href="posts/%[2]s"/><summary>Summary %[2]s</summary></entry>
+</feed>`, first, second)
+			return
+		}
+		w.Header().Set("Content-Type", urlType)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+<channel>
+  <title>Search results</title>
+  <item><title>Post %[1]s</title><link>/posts/%[1]s</link><description>&lt;p&gt;Summary %[1]s&lt;/p&gt;</description></item>
+  <item><title>Post %[2]s</title><link>https://other.example.org/posts/%[2]s</link><description>Summary %[2]s</description></item>
+</channel>
+</rss>`, first, second)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestOpenSearchPathTemplate(t *testing.T) {
+	srv, requests := openSearchServer(t, "application/rss+xml", "/search/{searchTerms}/{startPage?}", "")
+	en := OpenSearch(srv.URL + "/opensearch.xml")
+
+	results, err := en.Crawl("go rust", &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/search/go%20rust/1", "/search/go%20rust/2", "/search/go%20rust/3"}
+	if got := requests(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	assertResults(t, results, []Result{
+		{Title: "Post 1", Link: srv.URL + "/posts/1", Description: "Summary 1"},
+		{Title: "Post 2", Link: "https://other.example.org/posts/2", Description: "Summary 2"},
+		{Title: "Post 3", Link: srv.URL + "/posts/3", Description: "Summary 3"},
+		{Title: "Post 4", Link: "https://other.example.org/posts/4", Description: "Summary 4"},
+		{Title: "Post 5", Link: srv.URL + "/posts/5", Description: "Summary 5"},
+		{Title: "Post 6", Link: "https://other.example.org/posts/6", Description: "Summary 6"},
+	})
+}
+
+func TestOpenSearchStartIndex(t *testing.T) {
+	srv, requests := openSearchServer(t, "application/atom+xml", "/feed/search?q={searchTerms}&amp;start={startIndex?}&amp;n={count?}&amp;hl={language?}", `indexOffset="0"`)
+	en := OpenSearch(srv.URL + "/opensearch.xml")
+
+	results, err := en.Crawl("c++ & rust", &SearchOptions{Pages: 2, Lang: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/feed/search?q=c%2B%2B%20%26%20rust&start=0&n=10&hl=de",
+		"/feed/search?q=c%2B%2B%20%26%20rust&start=2&n=10&hl=de",
+	}
+	if got := requests(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	assertResults(t, results, []Result{
+		{Title: "Post 1", Link: srv.URL + "/posts/1", Description: "Summary 1"},
+		{Title: "Post 2", Link: srv.URL + "/feed/posts/2", Description: "Summary 2"},
+		{Title: "Post 3", Link: srv.URL + "/posts/3", Description: "Summary 3"},
+		{Title: "Post 4", Link: srv.URL + "/feed/posts/4", Description: "Summary 4"},
+	})
+}
+
+func TestOpenSearchCapabilities(t *testing.T) {
+	options := &SearchOptions{Pages: 5, Lang: "de"}
+	tests := []struct {
+		template   string
+		pagination string
+		languages  bool
+		warnings   []string
+	}{
+		{"/search?q={searchTerms}", PaginationNone, false, []string{"lang", "pages"}},
+		{"/search/{searchTerms}/{startPage?}", PaginationPage, false, []string{"lang"}},
+		{"/search?q={searchTerms}&amp;start={startIndex}&amp;hl={language?}", PaginationOffset, true, nil},
+	}
+	for _, test := range tests {
+		srv, requests := openSearchServer(t, "application/rss+xml", test.template, "")
+		en := OpenSearch(srv.URL + "/opensearch.xml")
+		if warnings := en.Check(options); len(warnings) != 2 {
+			t.Errorf("%s: got warnings %v before loading the description, want lang and pages", test.template, warnings)
+		}
+		if err := en.Prepare(context.Background(), options); err != nil {
+			t.Fatal(err)
+		}
+		caps := en.Info.Capabilities
+		if caps.Pagination != test.pagination || caps.Languages != test.languages {
+			t.Errorf("%s: got %+v", test.template, caps)
+		}
+		var warned []string
+		for _, warning := range en.Check(options) {
+			warned = append(warned, warning.Option)
+		}
+		if fmt.Sprint(warned) != fmt.Sprint(test.warnings) {
+			t.Errorf("%s: got warnings for %v, want %v", test.template, warned, test.warnings)
+		}
+
+		// searching works with the prepared engine
+		if _, err := en.Crawl("golang", &SearchOptions{Pages: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if got := requests(); len(got) != 1 {
+			t.Errorf("%s: got requests %q, want one search", test.template, got)
+		}
+	}
+}
+
+func TestOpenSearchConcurrentQueries(t *testing.T) {
+	srv, requests := openSearchServer(t, "application/rss+xml", "/search/{searchTerms}/{startPage?}", "")
+	en := OpenSearch(srv.URL + "/opensearch.xml")
+	options := &SearchOptions{Pages: 2}
+	if err := en.Prepare(context.Background(), options); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, query := range []string{"alpha", "beta"} {
+		wg.Add(1)
+		go func(query string) {
+			defer wg.Done()
+			if _, err := en.Crawl(query, options); err != nil {
+				t.Error(err)
+			}
+		}(query)
+	}
+	wg.Wait()
+	got := make(map[string]bool)
+	for _, request := range requests() {
+		got[request] = true
+	}
+	for _, want := range []string{"/search/alpha/1", "/search/alpha/2", "/search/beta/1", "/search/beta/2"} {
+		if !got[want] {
+			t.Errorf("missing request %s in %q", want, requests())
+		}
+	}
+}
+
+func TestOpenSearchUnevenPages(t *testing.T) {
+	sizes := []int{3, 1, 2}
+	var lock sync.Mutex
+	var starts []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/opensearch.xml" {
+			fmt.Fprintf(w, `<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">
+  <Url type="application/rss+xml" template="%s/rss?q={searchTerms}&amp;start={startIndex}"/>
+</OpenSearchDescription>`, srv.URL)
+			return
+		}
+		lock.Lock()
+		n := len(starts)
+		starts = append(starts, r.URL.Query().Get("start"))
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel>`)
+		for i := 0; n < len(sizes) && i < sizes[n]; i++ {
+			fmt.Fprintf(w, `<item><title>Post</title><link>/posts/%d/%d</link></item>`, n, i)
+		}
+		fmt.Fprint(w, `</channel></rss>`)
+	}))
+	defer srv.Close()
+	en := OpenSearch(srv.URL + "/opensearch.xml")
+
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Errorf("got %d results, want 6", len(results))
+	}
+	if want := []string{"1", "4", "5"}; fmt.Sprint(starts) != fmt.Sprint(want) {
+		t.Errorf("start = %q, want %q", starts, want)
+	}
+}
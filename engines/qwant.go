@@ -0,0 +1,170 @@
+package engines
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+// qwantLocales maps languages to the locale qwant uses for them when no
+// region is given
+var qwantLocales = map[string]string{
+	"bg": "bg_BG",
+	"ca": "ca_ES",
+	"cs": "cs_CZ",
+	"da": "da_DK",
+	"de": "de_DE",
+	"el": "el_GR",
+	"en": "en_US",
+	"es": "es_ES",
+	"et": "et_EE",
+	"fi": "fi_FI",
+	"fr": "fr_FR",
+	"hu": "hu_HU",
+	"it": "it_IT",
+	"ja": "ja_JP",
+	"ko": "ko_KR",
+	"nb": "nb_NO",
+	"nl": "nl_NL",
+	"pl": "pl_PL",
+	"pt": "pt_PT",
+	"ro": "ro_RO",
+	"sv": "sv_SE",
+	"th": "th_TH",
+	"zh": "zh_CN",
+}
+
+// qwantResponse is the part of a /v3/search/web response googly uses
+type qwantResponse struct {
+	Status string
+	Data   struct {
+		ErrorCode int `json:"error_code"`
+		ErrorData struct {
+			CaptchaUrl string
+		} `json:"error_data"`
+		Message []string
+		Result  struct {
+			Items struct {
+				Mainline []struct {
+					Type  string
+					Items []struct {
+						Title string
+						Url   string
+						Desc  string
+					}
+				}
+			}
+		}
+	}
+}
+
+const (
+	qwantPageSize = 10
+	// qwantRateLimited is the error code of requests exceeding the rate limit
+	qwantRateLimited = 24
+)
+
+func Qwant() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://api.qwant.com", path, url.Values{"locale": {qwantLocale(lang)}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+		},
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationOffset,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "qwant",
+			Short:        "Q",
+			DisplayName:  "Qwant",
+			Homepage:     "https://www.qwant.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("count", strconv.Itoa(qwantPageSize))
+			params.Set("offset", "0")
+			params.Set("device", "desktop")
+			if freshness := caps.timerange(options); freshness != "" {
+				params.Set("freshness", freshness)
+			}
+			if options.SafeSearch {
+				params.Set("safesearch", "2")
+			} else {
+				params.Set("safesearch", "1")
+			}
+			return Url("v3/search/web?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res qwantResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+			if res.Status != "success" {
+				link := r.Request.URL.String()
+				switch {
+				case res.Data.ErrorData.CaptchaUrl != "":
+					return nil, "", &BlockedError{URL: link}
+				case res.Data.ErrorCode == qwantRateLimited:
+					return nil, "", &RateLimitedError{URL: link}
+				}
+				message := strings.Join(res.Data.Message, ", ")
+				if message == "" {
+					message = "unknown error"
+				}
+				return nil, "", &APIError{URL: link, Code: strconv.Itoa(res.Data.ErrorCode), Message: message}
+			}
+
+			var results []Result
+			for _, group := range res.Data.Result.Items.Mainline {
+				if group.Type != "web" {
+					continue
+				}
+				for _, item := range group.Items {
+					results = append(results, Result{
+						Title:       item.Title,
+						Link:        item.Url,
+						Description: item.Desc,
+					})
+				}
+			}
+			if len(results) == 0 {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("offset", strconv.Itoa(page*qwantPageSize))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
+
+// qwantLocale turns a language like "fr" or "fr-CA" into a qwant locale
+// like fr_FR or fr_CA
+func qwantLocale(lang string) string {
+	parts := strings.SplitN(strings.Replace(lang, "-", "_", 1), "_", 2)
+	if len(parts) == 2 && parts[1] != "" {
+		return strings.ToLower(parts[0]) + "_" + strings.ToUpper(parts[1])
+	}
+	if locale, ok := qwantLocales[strings.ToLower(parts[0])]; ok {
+		return locale
+	}
+	return "en_US"
+}
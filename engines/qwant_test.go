@@ -0,0 +1,97 @@
+package engines
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestQwant(t *testing.T) {
+	srv := newFixtureServer(t, "application/json", "qwant.json", "qwant_2.json", "qwant_3.json")
+	en := Qwant()
+	srv.redirect(&en)
+
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 5, Lang: "fr-CA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only web results are used
+	assertResults(t, results, []Result{
+		{
+			Title:       "The Go Programming Language",
+			Link:        "https://go.dev/",
+			Description: "Go is an open source programming language that makes it simple to build secure, scalable systems.",
+		},
+		{
+			Title:       "Go (programming language) - Wikipedia",
+			Link:        "https://en.wikipedia.org/wiki/Go_(programming_language)",
+			Description: "Go is a statically typed, compiled high-level programming language designed at Google.",
+		},
+		{
+			Title:       "Go by Example",
+			Link:        "https://gobyexample.com/",
+			Description: "Go by Example is a hands-on introduction to Go using annotated example programs.",
+		},
+	})
+
+	// the third page is empty
+	if len(srv.requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(srv.requests))
+	}
+	for i, offset := range []string{"0", "10", "20"} {
+		qry := srv.query(i)
+		if got := qry.Get("offset"); got != offset {
+			t.Errorf("request %d: offset = %q, want %q", i, got, offset)
+		}
+		if got := qry.Get("locale"); got != "fr_CA" {
+			t.Errorf("request %d: locale = %q", i, got)
+		}
+	}
+}
+
+func TestQwantErrors(t *testing.T) {
+	tests := []struct {
+		fixture string
+		status  int
+		check   func(err error) bool
+	}{
+		{"qwant_captcha.json", http.StatusForbidden, func(err error) bool {
+			var blocked *BlockedError
+			return errors.As(err, &blocked)
+		}},
+		{"qwant_captcha.json", http.StatusOK, func(err error) bool {
+			var blocked *BlockedError
+			return errors.As(err, &blocked)
+		}},
+		{"qwant_ratelimit.json", http.StatusTooManyRequests, func(err error) bool {
+			var limited *RateLimitedError
+			return errors.As(err, &limited)
+		}},
+		{"qwant_ratelimit.json", http.StatusOK, func(err error) bool {
+			var limited *RateLimitedError
+			return errors.As(err, &limited)
+		}},
+		{"qwant_error.json", http.StatusBadRequest, func(err error) bool {
+			var apiErr *APIError
+			return errors.As(err, &apiErr) && apiErr.Code == "22" && apiErr.Message == "Invalid parameter: locale, Invalid parameter: count"
+		}},
+		{"qwant_error.json", http.StatusOK, func(err error) bool {
+			var apiErr *APIError
+			return errors.As(err, &apiErr) && apiErr.Code == "22"
+		}},
+	}
+	for _, test := range tests {
+		srv := newFixtureServer(t, "application/json", test.fixture)
+		srv.status = test.status
+		en := Qwant()
+		srv.redirect(&en)
+
+		results, err := en.Crawl("golang", &SearchOptions{Pages: 1})
+		if !test.check(err) {
+			t.Errorf("%s with status %d: got %T %v", test.fixture, test.status, err, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("%s with status %d: got %d results", test.fixture, test.status, len(results))
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package engines
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// queryParamDecoder decodes redirect links on the given path which carry
+// their target in one of the given query parameters
+func queryParamDecoder(path string, params ...string) func(link string) (string, bool) {
+	return func(link string) (string, bool) {
+		u, err := url.Parse(link)
+		if err != nil || u.Path != path {
+			return link, true
+		}
+		qry := u.Query()
+		for _, param := range params {
+			if target := qry.Get(param); target != "" {
+				return target, true
+			}
+		}
+		return link, false
+	}
+}
+
+// pathSegmentDecoder decodes redirect links on hosts ending in host which
+// carry their escaped target in a path segment starting with prefix, like
+// the /RU=https%3a%2f%2fexample.com%2f/ segment of yahoo links
+func pathSegmentDecoder(host string, prefix string) func(link string) (string, bool) {
+	return func(link string) (string, bool) {
+		u, err := url.Parse(link)
+		if err != nil || !strings.HasSuffix(u.Host, host) {
+			return link, true
+		}
+		for _, segment := range strings.Split(u.EscapedPath(), "/") {
+			if strings.HasPrefix(segment, prefix) {
+				if target, err := url.QueryUnescape(strings.TrimPrefix(segment, prefix)); err == nil {
+					return target, true
+				}
+			}
+		}
+		return link, false
+	}
+}
+
+// bingLinkDecoder decodes bing's /ck/a tracking links, which carry their
+// target base64 encoded in the u parameter behind an "a1" prefix
+func bingLinkDecoder(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || u.Path != "/ck/a" {
+		return link, true
+	}
+	target := u.Query().Get("u")
+	if !strings.HasPrefix(target, "a1") {
+		return link, false
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(target[2:], "="))
+	if err != nil {
+		return link, false
+	}
+	return string(decoded), true
+}
+
+// opaqueRedirectDecoder marks links on the given path as redirects whose
+// target can only be found by following them
+func opaqueRedirectDecoder(path string) func(link string) (string, bool) {
+	return func(link string) (string, bool) {
+		u, err := url.Parse(link)
+		return link, err != nil || u.Path != path
+	}
+}
+
+// resolveRedirect requests link without following redirects and returns the
+// location it redirects to
+func resolveRedirect(transport http.RoundTripper, link string, userAgent string) (string, error) {
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequest("GET", link, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	location, err := res.Location()
+	if err != nil {
+		return "", fmt.Errorf("%s did not redirect (status %d)", link, res.StatusCode)
+	}
+	return location.String(), nil
+}
@@ -0,0 +1,70 @@
+package engines
+
+import "testing"
+
+type decodeTest struct {
+	link    string
+	target  string
+	decoded bool
+}
+
+func testDecoder(t *testing.T, name string, decode func(link string) (string, bool), tests []decodeTest) {
+	t.Helper()
+	for _, test := range tests {
+		target, decoded := decode(test.link)
+		if target != test.target || decoded != test.decoded {
+			t.Errorf("%s: %s decoded to %s, %v, want %s, %v", name, test.link, target, decoded, test.target, test.decoded)
+		}
+	}
+}
+
+func TestQueryParamDecoder(t *testing.T) {
+	testDecoder(t, "duckduckgo", DuckDuckGo().DecodeLink, []decodeTest{
+		{"//duckduckgo.com/l/?uddg=https%3A%2F%2Fgo.dev%2Fdoc%2F&rut=5e2b0a9c1f", "https://go.dev/doc/", true},
+		{"//duckduckgo.com/l/?uddg=https%3A%2F%2Fexample.org%2Fsearch%3Fq%3Da%2520b%26lang%3Dde&rut=1", "https://example.org/search?q=a%20b&lang=de", true},
+		{"//duckduckgo.com/l/?rut=5e2b0a9c1f", "//duckduckgo.com/l/?rut=5e2b0a9c1f", false},
+		{"https://go.dev/doc/", "https://go.dev/doc/", true},
+	})
+	testDecoder(t, "google", Google().DecodeLink, []decodeTest{
+		{"/url?q=https://go.dev/doc/&sa=U&ved=2ahUKEwiA0&usg=AOvVaw3x", "https://go.dev/doc/", true},
+		{"/url?sa=t&url=https%3A%2F%2Fgo.dev%2Fblog%2F%3Fa%3D1&ved=2ahUKEwiA0", "https://go.dev/blog/?a=1", true},
+		{"/url?sa=U&ved=2ahUKEwiA0", "/url?sa=U&ved=2ahUKEwiA0", false},
+		{"https://go.dev/url", "https://go.dev/url", false},
+		{"https://go.dev/doc/", "https://go.dev/doc/", true},
+	})
+}
+
+func TestPathSegmentDecoder(t *testing.T) {
+	testDecoder(t, "yahoo", Yahoo().DecodeLink, []decodeTest{
+		{"https://r.search.yahoo.com/_ylt=AwrFPbnZ;_ylu=Y29sbwNiZjEEcG9zAzEEdnRpZAMEc2VjA3Ny/RV=2/RE=1710000000/RO=10/RU=https%3a%2f%2fgo.dev%2fdoc%2f/RK=2/RS=kV9Q0Wm-/", "https://go.dev/doc/", true},
+		{"https://r.search.yahoo.com/_ylt=AwrFPbnZ/RV=2/RU=https%3a%2f%2fexample.org%2fsearch%3fq%3da%2bb/RK=2/RS=x-/", "https://example.org/search?q=a+b", true},
+		{"https://r.search.yahoo.com/_ylt=AwrFPbnZ/RV=2/RK=2/", "https://r.search.yahoo.com/_ylt=AwrFPbnZ/RV=2/RK=2/", false},
+		{"https://go.dev/RU=https%3a%2f%2fexample.org/", "https://go.dev/RU=https%3a%2f%2fexample.org/", true},
+	})
+}
+
+func TestOpaqueRedirectDecoder(t *testing.T) {
+	testDecoder(t, "baidu", Baidu().DecodeLink, []decodeTest{
+		{"http://www.baidu.com/link?url=Kp3ExVn6Rbm0yBGPQ2Wm7bcH5KxNdT3x7iAYm_lEHW3", "http://www.baidu.com/link?url=Kp3ExVn6Rbm0yBGPQ2Wm7bcH5KxNdT3x7iAYm_lEHW3", false},
+		{"/link?url=Kp3ExVn6Rbm0yBGPQ2Wm7bcH5KxNdT3x7iAYm_lEHW3&wd=&eqid=d3c5", "/link?url=Kp3ExVn6Rbm0yBGPQ2Wm7bcH5KxNdT3x7iAYm_lEHW3&wd=&eqid=d3c5", false},
+		{"https://go.dev/doc/", "https://go.dev/doc/", true},
+		{"https://go.dev/link", "https://go.dev/link", false},
+	})
+}
+
+func TestBingLinkDecoder(t *testing.T) {
+	testDecoder(t, "bing", Bing().DecodeLink, []decodeTest{
+		// bing leaves out the padding
+		{"https://www.bing.com/ck/a?!&&p=4b6d0c2e1fJmltdHM9MTcx&ptn=3&ver=2&hsh=3&fclid=0f1e&u=a1aHR0cHM6Ly9nby5kZXYvZG9jLw&ntb=1", "https://go.dev/doc/", true},
+		{"https://www.bing.com/ck/a?!&&p=4b6d&u=a1aHR0cHM6Ly9nby5kZXYvZG9jLw==&ntb=1", "https://go.dev/doc/", true},
+		{"https://www.bing.com/ck/a?!&&p=4b6d&u=a1aHR0cHM6Ly9leC5vcmcvP2E&ntb=1", "https://ex.org/?a", true},
+		{"https://www.bing.com/ck/a?!&&p=4b6d&u=a1aHR0cHM6Ly9leC5vcmcvP2E%3D&ntb=1", "https://ex.org/?a", true},
+		// the url safe alphabet is used
+		{"https://www.bing.com/ck/a?u=a1aHR0cHM6Ly9leGFtcGxlLm9yZy9zZWFyY2g_cT1hIGImeD0xfg", "https://example.org/search?q=a b&x=1~", true},
+		{"https://www.bing.com/ck/a?u=a2aHR0cHM6Ly9nby5kZXYvZG9jLw", "https://www.bing.com/ck/a?u=a2aHR0cHM6Ly9nby5kZXYvZG9jLw", false},
+		{"https://www.bing.com/ck/a?u=aHR0cHM6Ly9nby5kZXYvZG9jLw", "https://www.bing.com/ck/a?u=aHR0cHM6Ly9nby5kZXYvZG9jLw", false},
+		{"https://www.bing.com/ck/a?p=4b6d&ntb=1", "https://www.bing.com/ck/a?p=4b6d&ntb=1", false},
+		{"https://www.bing.com/ck/a?u=a1!!not-base64", "https://www.bing.com/ck/a?u=a1!!not-base64", false},
+		{"https://go.dev/doc/", "https://go.dev/doc/", true},
+	})
+}
@@ -0,0 +1,65 @@
+package engines
+
+import (
+	"sort"
+	"sync"
+)
+
+// Info describes a search engine
+type Info struct {
+	Name string
+	// Short is an abbreviation used to tag results, e.g. G for Google
+	Short        string
+	DisplayName  string
+	Homepage     string
+	Capabilities Capabilities
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]func() SearchEngine{}
+)
+
+// Register makes a search engine available under the given name. Registering
+// an already known name replaces the previous engine.
+func Register(name string, constructor func() SearchEngine) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[name] = constructor
+}
+
+// Lookup creates the search engine registered under the given name
+func Lookup(name string) (SearchEngine, bool) {
+	registryLock.RLock()
+	constructor, ok := registry[name]
+	registryLock.RUnlock()
+	if !ok {
+		return SearchEngine{}, false
+	}
+	en := constructor()
+	en.Info.Name = name
+	return en, true
+}
+
+// Names returns the names of all registered search engines in alphabetical order
+func Names() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Registered returns the info of all registered search engines in alphabetical order
+func Registered() []Info {
+	var infos []Info
+	for _, name := range Names() {
+		if en, ok := Lookup(name); ok {
+			infos = append(infos, en.Info)
+		}
+	}
+	return infos
+}
@@ -0,0 +1,121 @@
+package engines
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly"
+)
+
+// ScholarExtra holds the citation details Google Scholar shows for a paper
+type ScholarExtra struct {
+	Authors []string
+	Venue   string
+	Year    int `json:",omitempty" xml:",omitempty"`
+	CitedBy int
+	// CitedByLink lists the papers citing this one
+	CitedByLink string `json:",omitempty" xml:",omitempty"`
+	PDF         string `json:",omitempty" xml:",omitempty"`
+	// ClusterID identifies all versions of the paper
+	ClusterID string `json:",omitempty" xml:",omitempty"`
+}
+
+var (
+	scholarYear    = regexp.MustCompile(`\b(1[89]|20)\d{2}\b`)
+	scholarCitedBy = regexp.MustCompile(`(\d+)\s*$`)
+	// scholarTypeTag matches the [PDF], [HTML], [BOOK] etc. tags in front of titles
+	scholarTypeTag = regexp.MustCompile(`^(\[[A-Z]+\]\s*)+`)
+)
+
+func Scholar() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://scholar.google.com", path, url.Values{"hl": {lang}})
+	}
+	caps := Capabilities{
+		DateRange:      true,
+		DateRangeYears: true,
+		Languages:      true,
+		Sort:           true,
+		Pagination:     PaginationOffset,
+	}
+	google := Google()
+	return SearchEngine{
+		Info: Info{
+			Name:         "scholar",
+			Short:        "GS",
+			DisplayName:  "Google Scholar",
+			Homepage:     "https://scholar.google.com",
+			Capabilities: caps,
+		},
+		browserConfig: google.browserConfig,
+		Url:           Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("q", query)
+			// scholar only filters by year
+			if options.From != nil {
+				params.Set("as_ylo", strconv.Itoa(options.From.Year()))
+			}
+			if options.To != nil {
+				params.Set("as_yhi", strconv.Itoa(options.To.Year()))
+			}
+			if options.Sort == SortDate {
+				params.Set("scisbd", "1")
+			}
+			return Url("scholar?"+params.Encode(), options.Lang)
+		},
+		Result: func(e *colly.HTMLElement) Result {
+			extra := ScholarExtra{
+				PDF: e.ChildAttr(".gs_or_ggsm a", "href"),
+			}
+			byline := strings.Split(strings.Replace(e.ChildText(".gs_a"), "\u00a0", " ", -1), " - ")
+			if len(byline) > 0 {
+				for _, author := range strings.Split(byline[0], ",") {
+					if author = strings.Trim(strings.TrimSpace(author), "…"); author != "" {
+						extra.Authors = append(extra.Authors, author)
+					}
+				}
+			}
+			if len(byline) > 1 {
+				venue := byline[1]
+				if year := scholarYear.FindAllString(venue, -1); len(year) > 0 {
+					extra.Year, _ = strconv.Atoi(year[len(year)-1])
+					venue = strings.Replace(venue, year[len(year)-1], "", 1)
+				}
+				extra.Venue = strings.Trim(strings.TrimSpace(venue), ",… ")
+			}
+			e.ForEach(".gs_fl a", func(_ int, link *colly.HTMLElement) {
+				href := link.Attr("href")
+				switch {
+				case strings.Contains(href, "cites="):
+					if match := scholarCitedBy.FindStringSubmatch(link.Text); match != nil {
+						extra.CitedBy, _ = strconv.Atoi(match[1])
+					}
+					extra.CitedByLink = link.Request.AbsoluteURL(href)
+				case strings.Contains(href, "cluster="):
+					if u, err := url.Parse(href); err == nil {
+						extra.ClusterID = u.Query().Get("cluster")
+					}
+				}
+			})
+			return Result{
+				Title:       scholarTypeTag.ReplaceAllString(e.ChildText("h3.gs_rt"), ""),
+				Link:        e.ChildAttr("h3.gs_rt a", "href"),
+				Description: e.ChildText(".gs_rs"),
+				Extra:       extra,
+			}
+		},
+		Pagination: func(page int, options *SearchOptions, e *colly.HTMLElement) string {
+			url := e.Request.URL
+			qry := url.Query()
+			qry.Set("start", strconv.Itoa((page-1)*10))
+			url.RawQuery = qry.Encode()
+			return url.String()
+		},
+		Blocked:            google.Blocked,
+		resultSelector:     "#gs_res_ccl_mid > .gs_r.gs_or",
+		paginationSelector: "#gs_n td:last-child a, button.gs_btnPR:not([disabled])",
+	}
+}
@@ -0,0 +1,144 @@
+package engines
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// SearXNGExtra holds the details a SearXNG instance reports about a result
+type SearXNGExtra struct {
+	// Engines lists the engines SearXNG found the result on
+	Engines []string
+	Score   float64
+}
+
+// searxngResponse is the part of a format=json response googly uses
+type searxngResponse struct {
+	Results []struct {
+		Url           string
+		Title         string
+		Content       string
+		Engines       []string
+		Score         float64
+		PublishedDate *string
+	}
+}
+
+// searxngDateLayouts lists the layouts SearXNG uses for publishedDate
+var searxngDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// SearXNG searches the SearXNG instances at the given base urls, which need
+// to have the json format enabled. The first instance is tried first, the
+// others only if searching the ones before failed. Without any instances
+// searching fails.
+func SearXNG(instances ...string) SearchEngine {
+	if len(instances) == 0 {
+		en := searxng("")
+		en.setup = func(transport http.RoundTripper, userAgent string) error {
+			return errors.New("no SearXNG instances given")
+		}
+		return en
+	}
+	en := searxng(instances[0])
+	if len(instances) > 1 {
+		fallback := SearXNG(instances[1:]...)
+		en.fallback = &fallback
+	}
+	return en
+}
+
+func searxng(base string) SearchEngine {
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	Url := func(path string, lang string) string {
+		return getUrl(base, path, url.Values{"language": {lang}})
+	}
+	caps := Capabilities{
+		Timeranges: map[string]string{
+			"hour":  "day",
+			"day":   "day",
+			"week":  "week",
+			"month": "month",
+			"year":  "year",
+		},
+		Languages:  true,
+		SafeSearch: true,
+		Pagination: PaginationPage,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "searxng",
+			Short:        "SX",
+			DisplayName:  "SearXNG",
+			Homepage:     base,
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("format", "json")
+			params.Set("pageno", "1")
+			if timeRange := caps.timerange(options); timeRange != "" {
+				params.Set("time_range", timeRange)
+			}
+			if options.SafeSearch {
+				params.Set("safesearch", "2")
+			}
+			return Url("search?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res searxngResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+
+			var results []Result
+			for _, item := range res.Results {
+				result := Result{
+					Title:       item.Title,
+					Link:        item.Url,
+					Description: item.Content,
+					Extra: SearXNGExtra{
+						Engines: item.Engines,
+						Score:   item.Score,
+					},
+				}
+				if item.PublishedDate != nil {
+					for _, layout := range searxngDateLayouts {
+						if date, err := time.Parse(layout, *item.PublishedDate); err == nil {
+							result.Date = &date
+							break
+						}
+					}
+				}
+				results = append(results, result)
+			}
+			if len(results) == 0 {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("pageno", strconv.Itoa(page+1))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package engines
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSearXNGFailover(t *testing.T) {
+	var limited int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&limited, 1)
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer down.Close()
+	srv := newFixtureServer(t, "application/json", "searxng.json", "searxng_2.json")
+	en := SearXNG(down.URL, srv.URL+"/searx")
+
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 2, Lang: "de"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&limited); n != 1 {
+		t.Errorf("first instance got %d requests, want 1", n)
+	}
+	assertResults(t, results, []Result{
+		{
+			Title:       "The Go Programming Language",
+			Link:        "https://go.dev/",
+			Description: "Go is an open source programming language that makes it simple to build secure, scalable systems.",
+		},
+		{
+			Title:       "Go (programming language) - Wikipedia",
+			Link:        "https://en.wikipedia.org/wiki/Go_(programming_language)",
+			Description: "Go is a statically typed, compiled high-level programming language designed at Google.",
+		},
+		{
+			Title:       "Go by Example",
+			Link:        "https://gobyexample.com/",
+			Description: "Go by Example is a hands-on introduction to Go using annotated example programs.",
+		},
+	})
+	extras := []SearXNGExtra{
+		{Engines: []string{"duckduckgo", "brave", "mojeek"}, Score: 7.5},
+		{Engines: []string{"brave"}, Score: 0.5},
+		{Engines: []string{"mojeek"}, Score: 0.09090909090909091},
+	}
+	for i, extra := range extras {
+		if !reflect.DeepEqual(results[i].Extra, extra) {
+			t.Errorf("result %d: extra = %+v, want %+v", i, results[i].Extra, extra)
+		}
+		if results[i].Sources[0].Engine != "searxng" {
+			t.Errorf("result %d: engine = %q", i, results[i].Sources[0].Engine)
+		}
+	}
+	if date := results[1].Date; date == nil || !date.Equal(time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %v", date)
+	}
+	if results[0].Date != nil {
+		t.Errorf("date = %v, want none", results[0].Date)
+	}
+
+	if len(srv.requests) != 2 {
+		t.Fatalf("second instance got %d requests, want 2", len(srv.requests))
+	}
+	for i, pageno := range []string{"1", "2"} {
+		if path := srv.requests[i].URL.Path; path != "/searx/search" {
+			t.Errorf("request %d: path = %s", i, path)
+		}
+		qry := srv.query(i)
+		if got := qry.Get("pageno"); got != pageno {
+			t.Errorf("request %d: pageno = %q, want %q", i, got, pageno)
+		}
+		if got := qry.Get("format"); got != "json" {
+			t.Errorf("request %d: format = %q", i, got)
+		}
+		if got := qry.Get("language"); got != "de" {
+			t.Errorf("request %d: language = %q", i, got)
+		}
+	}
+}
+
+func TestSearXNGTimeRange(t *testing.T) {
+	en := SearXNG("https://searx.example.org")
+	tests := map[string]string{
+		"":      "",
+		"hour":  "day",
+		"day":   "day",
+		"week":  "week",
+		"month": "month",
+		"year":  "year",
+	}
+	for timerange, want := range tests {
+		qry := parseQuery(t, en.SearchUrl("golang", &SearchOptions{Timerange: timerange}))
+		got, ok := qry["time_range"]
+		if want == "" && ok {
+			t.Errorf("%q: time_range = %q, want none", timerange, got)
+		} else if want != "" && qry.Get("time_range") != want {
+			t.Errorf("%q: time_range = %q, want %q", timerange, got, want)
+		}
+	}
+}
+
+func TestSearXNGWithoutInstances(t *testing.T) {
+	en := SearXNG()
+	results, err := en.Crawl("golang", &SearchOptions{Pages: 1})
+	if err == nil || err.Error() != "no SearXNG instances given" {
+		t.Errorf("got %v, want an error", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("got %d results", len(results))
+	}
+}
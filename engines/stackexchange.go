@@ -0,0 +1,153 @@
+package engines
+
+import (
+	"encoding/json"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// StackExchangeExtra holds the details Stack Exchange reports about a question
+type StackExchangeExtra struct {
+	Score    int
+	Answers  int
+	Views    int
+	Answered bool
+	// Accepted reports whether an answer has been accepted
+	Accepted bool
+	Tags     []string
+	Updated  time.Time
+}
+
+// stackExchangeResponse is the part of a /search/advanced response googly uses
+type stackExchangeResponse struct {
+	ErrorID      int    `json:"error_id"`
+	ErrorName    string `json:"error_name"`
+	ErrorMessage string `json:"error_message"`
+	HasMore      bool   `json:"has_more"`
+	Items        []struct {
+		Title            string
+		Link             string
+		Body             string
+		Score            int
+		AnswerCount      int   `json:"answer_count"`
+		ViewCount        int   `json:"view_count"`
+		IsAnswered       bool  `json:"is_answered"`
+		AcceptedAnswerID int   `json:"accepted_answer_id"`
+		CreationDate     int64 `json:"creation_date"`
+		LastActivityDate int64 `json:"last_activity_date"`
+		Tags             []string
+	}
+}
+
+const (
+	stackExchangePageSize = 20
+	// stackExchangeExcerpt is the length descriptions are cut to
+	stackExchangeExcerpt = 300
+	// stackExchangeThrottled is the error id of requests exceeding the quota
+	stackExchangeThrottled = 502
+)
+
+// StackExchange searches the questions of the Stack Exchange site selected
+// by SearchOptions.Site, stackoverflow by default
+func StackExchange() SearchEngine {
+	Url := func(path string, lang string) string {
+		return getUrl("https://api.stackexchange.com/2.3/", path, nil)
+	}
+	caps := Capabilities{
+		Timeranges: everyTimerange(),
+		DateRange:  true,
+		Sort:       true,
+		Sites:      true,
+		Pagination: PaginationPage,
+	}
+	return SearchEngine{
+		Info: Info{
+			Name:         "stackexchange",
+			Short:        "SE",
+			DisplayName:  "Stack Exchange",
+			Homepage:     "https://stackexchange.com",
+			Capabilities: caps,
+		},
+		browserConfig: BrowserConfig{
+			chrome:  true,
+			firefox: true,
+		},
+		Url: Url,
+		SearchUrl: func(query string, options *SearchOptions) string {
+			site := options.Site
+			if site == "" {
+				site = "stackoverflow"
+			}
+			params := url.Values{}
+			params.Set("q", query)
+			params.Set("site", site)
+			params.Set("page", "1")
+			params.Set("pagesize", strconv.Itoa(stackExchangePageSize))
+			params.Set("filter", "withbody")
+			params.Set("order", "desc")
+			if options.Sort == SortDate {
+				params.Set("sort", "creation")
+			} else {
+				params.Set("sort", "relevance")
+			}
+			from, to := searchPeriod(&caps, options)
+			if !from.IsZero() {
+				params.Set("fromdate", strconv.FormatInt(from.Unix(), 10))
+			}
+			if !to.IsZero() {
+				params.Set("todate", strconv.FormatInt(to.Unix(), 10))
+			}
+			return Url("search/advanced?"+params.Encode(), options.Lang)
+		},
+		Parse: func(page int, options *SearchOptions, r *colly.Response) ([]Result, string, error) {
+			var res stackExchangeResponse
+			if err := json.Unmarshal(r.Body, &res); err != nil {
+				return nil, "", err
+			}
+			if res.ErrorID != 0 {
+				link := r.Request.URL.String()
+				if res.ErrorID == stackExchangeThrottled {
+					return nil, "", &RateLimitedError{URL: link}
+				}
+				return nil, "", &APIError{URL: link, Code: res.ErrorName, Message: res.ErrorMessage}
+			}
+
+			var results []Result
+			for _, item := range res.Items {
+				description := []rune(strings.Join(strings.Fields(stripTags(item.Body)), " "))
+				if len(description) > stackExchangeExcerpt {
+					description = append(description[:stackExchangeExcerpt], '…')
+				}
+				date := time.Unix(item.CreationDate, 0).UTC()
+				results = append(results, Result{
+					Title:       html.UnescapeString(item.Title),
+					Link:        item.Link,
+					Description: string(description),
+					Date:        &date,
+					Extra: StackExchangeExtra{
+						Score:    item.Score,
+						Answers:  item.AnswerCount,
+						Views:    item.ViewCount,
+						Answered: item.IsAnswered,
+						Accepted: item.AcceptedAnswerID != 0,
+						Tags:     item.Tags,
+						Updated:  time.Unix(item.LastActivityDate, 0).UTC(),
+					},
+				})
+			}
+			if !res.HasMore {
+				return results, "", nil
+			}
+			next := *r.Request.URL
+			qry := next.Query()
+			qry.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = qry.Encode()
+			return results, next.String(), nil
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package engines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of outcomes reported in EngineStatus.Status
+const (
+	StatusOK          = "ok"
+	StatusRateLimited = "rate-limited"
+	StatusBlocked     = "blocked"
+	StatusHTTP        = "http-status"
+	StatusNetwork     = "network"
+	StatusParse       = "parse"
+	StatusAPI         = "api-error"
+	StatusTimeout     = "timeout"
+	StatusCanceled    = "canceled"
+	StatusUnknown     = "error"
+)
+
+// EngineStatus reports how the search on a single engine went
+type EngineStatus struct {
+	Engine  string
+	Status  string
+	Error   string `json:",omitempty" xml:",omitempty"`
+	Elapsed time.Duration
+	Results int
+}
+
+// NewEngineStatus creates the status of a search on the given engine which
+// returned results and err after running for elapsed
+func NewEngineStatus(engine string, results int, elapsed time.Duration, err error) EngineStatus {
+	status := EngineStatus{
+		Engine:  engine,
+		Status:  ErrorKind(err),
+		Elapsed: elapsed,
+		Results: results,
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// ErrorKind classifies err as one of the Status kinds, StatusOK for a nil error
+func ErrorKind(err error) string {
+	var rateLimited *RateLimitedError
+	var blocked *BlockedError
+	var status *StatusError
+	var network *NetworkError
+	var parse *ParseError
+	var api *APIError
+	switch {
+	case err == nil:
+		return StatusOK
+	case errors.Is(err, context.DeadlineExceeded):
+		return StatusTimeout
+	case errors.Is(err, context.Canceled):
+		return StatusCanceled
+	case errors.As(err, &rateLimited):
+		return StatusRateLimited
+	case errors.As(err, &blocked):
+		return StatusBlocked
+	case errors.As(err, &status):
+		return StatusHTTP
+	case errors.As(err, &network):
+		return StatusNetwork
+	case errors.As(err, &parse):
+		return StatusParse
+	case errors.As(err, &api):
+		return StatusAPI
+	default:
+		return StatusUnknown
+	}
+}
+
+// EngineError is an error which occurred while searching on a single engine
+type EngineError struct {
+	Engine string
+	Err    error
+}
+
+func (e *EngineError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Engine, e.Err)
+}
+
+func (e *EngineError) Unwrap() error {
+	return e.Err
+}
+
+// EnginesError is returned by Combined when engines failed, it contains an
+// EngineError for every failed engine
+type EnginesError struct {
+	Total  int
+	Errors []error
+}
+
+func (e *EnginesError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d of %d engines failed: %s", len(e.Errors), e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *EnginesError) Unwrap() []error {
+	return e.Errors
+}
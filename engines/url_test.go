@@ -0,0 +1,131 @@
+package engines
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var urlQueries = []string{
+	"c++ & rust",
+	`"exact phrase"`,
+	"50% off",
+	"東京 ラーメン",
+	"a=b#c?d",
+}
+
+func TestGetUrl(t *testing.T) {
+	for _, query := range urlQueries {
+		link := getUrl("https://example.org/search?hl=de", "", url.Values{"q": {query}, "empty": {""}})
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("%q: invalid url %s: %v", query, link, err)
+		}
+		qry := u.Query()
+		if got := qry.Get("q"); got != query {
+			t.Errorf("%q: q = %q in %s", query, got, link)
+		}
+		if got := qry.Get("hl"); got != "de" {
+			t.Errorf("%q: hl = %q in %s", query, got, link)
+		}
+		if len(qry) != 2 {
+			t.Errorf("%q: unexpected parameters in %s", query, link)
+		}
+	}
+}
+
+func TestGetUrlReplacesParams(t *testing.T) {
+	link := getUrl("https://example.org/", "search?q=old&page=2", url.Values{"q": {"new"}})
+	if link != "https://example.org/search?page=2&q=new" {
+		t.Errorf("got %s", link)
+	}
+}
+
+func TestDefinitionUrl(t *testing.T) {
+	dir, err := ioutil.TempDir("", "googly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	definitions := map[string]string{
+		"querytemplate.json": `{"name":"querytemplate","url":"https://ex.org/search?q={query}&hl={lang}","selectors":{"result":".r"}}`,
+		"pathtemplate.json":  `{"name":"pathtemplate","url":"https://ex.org/search/{query}","selectors":{"result":".r"}}`,
+		"params.json":        `{"name":"params","url":"https://ex.org/search","params":{"q":"{query}"},"selectors":{"result":".r"}}`,
+	}
+	for name, definition := range definitions {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(definition), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := LoadDefinitions(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	options := &SearchOptions{Lang: "en"}
+	for _, query := range urlQueries {
+		for _, name := range []string{"querytemplate", "params"} {
+			en, _ := Lookup(name)
+			link := en.SearchUrl(query, options)
+			u, err := url.Parse(link)
+			if err != nil {
+				t.Fatalf("%s %q: invalid url %s: %v", name, query, link, err)
+			}
+			if got := u.Query().Get("q"); got != query {
+				t.Errorf("%s %q: q = %q in %s", name, query, got, link)
+			}
+		}
+
+		en, _ := Lookup("pathtemplate")
+		link := en.SearchUrl(query, options)
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("pathtemplate %q: invalid url %s: %v", query, link, err)
+		}
+		if got := strings.TrimPrefix(u.Path, "/search/"); got != query {
+			t.Errorf("pathtemplate %q: path segment = %q in %s", query, got, link)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			t.Errorf("pathtemplate %q: query leaked out of the path in %s", query, link)
+		}
+	}
+}
+
+// TestSearchUrls checks that every registered engine keeps each word of the
+// query inside parameter values or the path, so that none of them gets
+// split up
+func TestSearchUrls(t *testing.T) {
+	options := &SearchOptions{Lang: "en", Pages: 1}
+	for _, name := range Names() {
+		en, _ := Lookup(name)
+		if en.setup != nil {
+			// the url template is only known after setup
+			continue
+		}
+		for _, query := range urlQueries {
+			link := en.SearchUrl(query, options)
+			u, err := url.Parse(link)
+			if err != nil {
+				t.Errorf("%s %q: invalid url %s: %v", name, query, link, err)
+				continue
+			}
+			var values []string
+			for param, paramValues := range u.Query() {
+				for _, word := range strings.Fields(query) {
+					if word = strings.Trim(word, `"&%`); word != "" && strings.TrimSpace(param) == word {
+						t.Errorf("%s %q: parameter %q was split off the query in %s", name, query, param, link)
+					}
+				}
+				values = append(values, paramValues...)
+			}
+			joined := u.Path + " " + strings.Join(values, " ")
+			for _, word := range strings.Fields(query) {
+				if !strings.Contains(joined, word) {
+					t.Errorf("%s %q: %q is missing from the parameters of %s", name, query, word, link)
+				}
+			}
+		}
+	}
+}
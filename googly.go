@@ -3,85 +3,357 @@ package main
 import (
 	"github.com/deletescape/googly/engines"
 
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"os"
-	"time"
+	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/akamensky/argparse"
 )
 
 const UA = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/77.0.3865.78 Safari/537.36 Vivaldi/2.8.1664.35"
 
+// Exit codes returned by googly. Results found before an error are still
+// printed before exiting.
+//
+//	0  search completed
+//	1  invalid arguments or an unexpected error
+//	2  network error
+//	3  a search engine answered with an unexpected HTTP status
+//	4  rate limited by a search engine
+//	5  blocked by a captcha
+//	6  a search engine response could not be parsed
+//	7  the search timed out
+//	8  a search engine reported an error
+//	130  the search was interrupted
+const (
+	exitOK = iota
+	exitUsage
+	exitNetwork
+	exitStatus
+	exitRateLimited
+	exitBlocked
+	exitParse
+	exitTimeout
+	exitAPI
+	exitInterrupted = 130
+)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "engines" {
+		listEngines(os.Args[1:])
+		return
+	}
+
+	if err := engines.LoadDefinitions(definitionDir()); err != nil {
+		exitWithUsageError(err)
+	}
+
 	parser := argparse.NewParser("", "Search using various search engines from the comfort of your terminal")
-	query := parser.String("q", "query", &argparse.Options{Required: true, Help: "String to query search engine for",})
-	lang := parser.String("l", "lang", &argparse.Options{Help: "Search result language", Default: "en",})
+	query := parser.String("q", "query", &argparse.Options{Required: true, Help: "String to query search engine for"})
+	lang := parser.String("l", "lang", &argparse.Options{Help: "Search result language, defaults to en"})
 	pages := parser.Int("p", "pages", &argparse.Options{Help: "The amount of pages to scrape", Default: 5})
 	format := parser.Selector("f", "format", []string{"cli", "json", "xml"}, &argparse.Options{Help: "Output format", Default: "cli"})
-	engine := parser.Selector("e", "engine", []string{"google", "ecosia", "startpage", "yahoo", "ddg", "naver", "combined"}, &argparse.Options{Help: "Search engine to use", Default: "google"})
+	engine := parser.String("e", "engine", &argparse.Options{Help: "Search engine to use, one of " + strings.Join(append(engines.Names(), "combined"), ", "), Default: "google"})
+	engineFiles := parser.List("", "engine-file", &argparse.Options{Help: "Load an additional engine definition file, can be given multiple times"})
+	openSearch := parser.String("", "opensearch", &argparse.Options{Help: "Search using the OpenSearch description document at this url, or the opensearch engine registered under this name"})
+	searxng := parser.List("", "searxng", &argparse.Options{Help: "Base url of a SearXNG instance to search with -e searxng, can be given multiple times to fail over to the next instance"})
+	combined := parser.String("", "engines", &argparse.Options{Help: "Comma separated list of engines used by the combined engine", Default: "google,ecosia,ddg"})
+	merge := parser.Selector("", "merge", []string{engines.MergeFusion, engines.MergeRoundRobin}, &argparse.Options{Help: "How the combined engine ranks results", Default: engines.MergeFusion})
+	weights := parser.String("", "weights", &argparse.Options{Help: "Engine weights for ranking combined results, e.g. google=1.0,ddg=0.8"})
 	verbose := parser.Flag("v", "verbose", &argparse.Options{Help: "Print more request infos"})
 	from := parser.String("", "from", &argparse.Options{Help: "Start date for the search"})
 	to := parser.String("", "to", &argparse.Options{Help: "End date for the search"})
-	time := parser.Selector("t", "time-range", []string{"any", "hour", "day", "week", "month", "year"}, &argparse.Options{Help: "Time range in which to search", Default: "any"})
+	timerange := parser.Selector("t", "time-range", []string{"any", "hour", "day", "week", "month", "year"}, &argparse.Options{Help: "Time range in which to search", Default: "any"})
+	timeout := parser.String("", "timeout", &argparse.Options{Help: "Maximum duration of the whole search, e.g. 30s"})
+	engineTimeout := parser.String("", "engine-timeout", &argparse.Options{Help: "Maximum duration of the search on a single engine, e.g. 10s"})
+	sort := parser.Selector("", "sort", []string{engines.SortRelevance, engines.SortDate}, &argparse.Options{Help: "Order of the results", Default: engines.SortRelevance})
+	site := parser.String("", "site", &argparse.Options{Help: "Site to search on engines covering several sites, e.g. stackoverflow or serverfault"})
+	safeSearch := parser.Flag("", "safe-search", &argparse.Options{Help: "Filter explicit results"})
+	dedupe := parser.String("", "dedupe", &argparse.Options{Help: "Comma separated rules for detecting duplicate links in combined results: scheme, www, slash, fragment, trackers, encoding, all or none", Default: "all"})
+	cleanLinks := parser.Flag("", "clean-links", &argparse.Options{Help: "Remove tracking parameters from result links"})
+	resolveRedirects := parser.Flag("", "resolve-redirects", &argparse.Options{Help: "Follow redirect links whose target can't be decoded offline"})
+	requireAll := parser.Flag("", "require-all", &argparse.Options{Help: "Fail the combined engine if any of its engines fails"})
+	strict := parser.Flag("", "strict", &argparse.Options{Help: "Fail instead of warning when an engine does not support an option"})
 	err := parser.Parse(os.Args)
+	if err != nil {
+		exitWithUsageError(err)
+	}
+
+	if err := loadDefinitionFiles(*engineFiles); err != nil {
+		exitWithUsageError(err)
+	}
+	registerSearXNG(*searxng)
+	if *openSearch != "" {
+		name := *openSearch
+		if strings.Contains(name, "://") {
+			engines.Register("opensearch", func() engines.SearchEngine {
+				return engines.OpenSearch(*openSearch)
+			})
+			name = "opensearch"
+		}
+		if *engine != "combined" {
+			*engine = name
+		}
+	}
+	if _, ok := engines.Lookup(*engine); !ok && *engine != "combined" {
+		exitWithUsageError(fmt.Errorf("unknown search engine %q", *engine))
+	}
+
+	overallTimeout, err := parseDuration(*timeout)
+	if err != nil {
+		exitWithUsageError(err)
+	}
+	perEngineTimeout, err := parseDuration(*engineTimeout)
+	if err != nil {
+		exitWithUsageError(err)
+	}
+
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		// restore the default behaviour so that a second interrupt kills googly right away
+		<-sigCtx.Done()
+		stop()
+	}()
+	ctx := sigCtx
+	if overallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, overallTimeout)
+		defer cancel()
+	}
+
+	engineWeights, err := parseWeights(*weights)
+	if err != nil {
+		exitWithUsageError(err)
+	}
+
+	dedupeRules, err := engines.ParseCanonicalRules(*dedupe)
+	if err != nil {
+		exitWithUsageError(err)
+	}
+
+	options := &engines.SearchOptions{
+		Lang:             *lang,
+		Pages:            *pages,
+		Verbose:          *verbose,
+		Timerange:        *timerange,
+		Timeout:          perEngineTimeout,
+		SafeSearch:       *safeSearch,
+		Merge:            *merge,
+		Weights:          engineWeights,
+		RequireAll:       *requireAll,
+		Dedupe:           dedupeRules,
+		CleanLinks:       *cleanLinks,
+		ResolveRedirects: *resolveRedirects,
+		Sort:             *sort,
+		Site:             *site,
+	}
+	if *from != "" {
+		tmp := parseDate(*from)
+		options.From = &tmp
+	}
+	if *to != "" {
+		tmp := parseDate(*to)
+		options.To = &tmp
+	}
+
+	err = crawl(ctx, *engine, strings.Split(*combined, ","), *query, *format, options, *strict)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
+}
 
-	crawl(*engine, *query, *lang, *pages, *format, *verbose, *from, *to, *time)
+func exitWithUsageError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(exitUsage)
 }
 
-func crawl(engine string, query string, lang string, pages int, format string, verbose bool, from string, to string, timerange string) {
-	var searchEngine engines.SearchEngine
+// definitionDir is the directory engine definitions are loaded from on startup
+func definitionDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "googly", "engines")
+}
 
-	switch engine {
-	case "google":
-		searchEngine = engines.Google()
-	case "ecosia":
-		searchEngine = engines.Ecosia()
-	case "startpage":
-		searchEngine = engines.Startpage()
-	case "yahoo":
-		searchEngine = engines.Yahoo()
-	case "ddg":
-		searchEngine = engines.DuckDuckGo()
-	case "naver":
-		searchEngine = engines.Naver()
+func loadDefinitionFiles(files []string) error {
+	for _, file := range files {
+		if err := engines.LoadDefinitionFile(file); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	var fromTime *time.Time
-	var toTime *time.Time
+func exitCode(err error) int {
+	switch engines.ErrorKind(err) {
+	case engines.StatusCanceled:
+		return exitInterrupted
+	case engines.StatusTimeout:
+		return exitTimeout
+	case engines.StatusRateLimited:
+		return exitRateLimited
+	case engines.StatusBlocked:
+		return exitBlocked
+	case engines.StatusHTTP:
+		return exitStatus
+	case engines.StatusNetwork:
+		return exitNetwork
+	case engines.StatusParse:
+		return exitParse
+	case engines.StatusAPI:
+		return exitAPI
+	default:
+		return exitUsage
+	}
+}
 
-	if from != "" {
-		tmp := parseDate(from)
-		fromTime = &tmp
+// registerSearXNG makes the given SearXNG instances available as the searxng engine
+func registerSearXNG(instances []string) {
+	if len(instances) == 0 {
+		return
 	}
+	engines.Register("searxng", func() engines.SearchEngine {
+		return engines.SearXNG(instances...)
+	})
+}
 
-	if to != "" {
-		tmp := parseDate(to)
-		toTime = &tmp
+func listEngines(args []string) {
+	parser := argparse.NewParser("engines", "List the available search engines")
+	engineFiles := parser.List("", "engine-file", &argparse.Options{Help: "Load an additional engine definition file, can be given multiple times"})
+	searxng := parser.List("", "searxng", &argparse.Options{Help: "Base url of a SearXNG instance, can be given multiple times"})
+	err := parser.Parse(args)
+	if err != nil {
+		exitWithUsageError(err)
 	}
 
-	options := &engines.SearchOptions{
-		Lang: lang,
-		Pages: pages,
-		Verbose: verbose,
-		From: fromTime,
-		To: toTime,
-		Timerange: timerange,
+	if err := engines.LoadDefinitions(definitionDir()); err != nil {
+		exitWithUsageError(err)
+	}
+	if err := loadDefinitionFiles(*engineFiles); err != nil {
+		exitWithUsageError(err)
+	}
+	registerSearXNG(*searxng)
+
+	for _, info := range engines.Registered() {
+		fmt.Printf("%-12s %-12s %s\n", info.Name, info.DisplayName, info.Homepage)
+		if features := capabilities(info.Capabilities); len(features) > 0 {
+			fmt.Printf("%-12s supports: %s\n", "", strings.Join(features, ", "))
+		}
+	}
+}
+
+// capabilities describes the given capabilities in a human readable way
+func capabilities(caps engines.Capabilities) []string {
+	var features []string
+	if len(caps.Timeranges) > 0 {
+		var timeranges []string
+		for _, timerange := range []string{"hour", "day", "week", "month", "year"} {
+			if caps.Timeranges[timerange] == timerange {
+				timeranges = append(timeranges, timerange)
+			}
+		}
+		features = append(features, "time ranges ("+strings.Join(timeranges, ", ")+")")
+	}
+	if caps.DateRange && caps.DateRangeYears {
+		features = append(features, "date ranges (whole years)")
+	} else if caps.DateRange {
+		features = append(features, "date ranges")
+	}
+	if caps.Languages {
+		features = append(features, "languages")
+	}
+	if caps.SafeSearch {
+		features = append(features, "safe search")
+	}
+	if caps.Sort {
+		features = append(features, "sorting by date")
+	}
+	if caps.Sites {
+		features = append(features, "site selection")
+	}
+	if caps.Pagination != "" && caps.Pagination != engines.PaginationNone {
+		features = append(features, "pagination ("+caps.Pagination+")")
+	}
+	return features
+}
+
+type engineMetadata struct {
+	Name         string
+	DisplayName  string
+	Capabilities engines.Capabilities
+	Status       engines.EngineStatus
+}
+
+type jsonOutput struct {
+	Query    string
+	Engines  []engineMetadata
+	Warnings []string
+	Results  []engines.Result
+}
+
+func crawl(ctx context.Context, engine string, combined []string, query string, format string, options *engines.SearchOptions, strict bool) error {
+	var searchEngines []engines.SearchEngine
+	if engine == "combined" {
+		for _, name := range combined {
+			en, ok := engines.Lookup(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("unknown search engine %q", name)
+			}
+			searchEngines = append(searchEngines, en)
+		}
+	} else {
+		en, _ := engines.Lookup(engine)
+		searchEngines = append(searchEngines, en)
+	}
+
+	output := jsonOutput{Query: query}
+	for i := range searchEngines {
+		en := &searchEngines[i]
+		// some engines only know what they support after fetching something,
+		// if that fails searching them fails as well
+		if err := en.Prepare(ctx, options); err != nil && options.Verbose {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		output.Engines = append(output.Engines, engineMetadata{
+			Name:         en.Info.Name,
+			DisplayName:  en.Info.DisplayName,
+			Capabilities: en.Info.Capabilities,
+		})
+		for _, warning := range en.Check(options) {
+			fmt.Fprintln(os.Stderr, "warning:", warning)
+			output.Warnings = append(output.Warnings, warning.String())
+		}
+	}
+	if strict && len(output.Warnings) > 0 {
+		return fmt.Errorf("%d search options are not supported by the selected engines", len(output.Warnings))
+	}
+	if options.Lang == "" {
+		options.Lang = "en"
 	}
 
 	var results = []engines.Result{}
+	var statuses []engines.EngineStatus
+	var err error
 	if engine == "combined" {
-		results = engines.Combined(query, options, engines.Google(), engines.Ecosia(), engines.DuckDuckGo())
+		results, statuses, err = engines.CombinedContext(ctx, query, options, searchEngines...)
 	} else {
-		results = searchEngine.Crawl(query, options)
+		start := time.Now()
+		results, err = searchEngines[0].CrawlContext(ctx, query, options)
+		statuses = append(statuses, engines.NewEngineStatus(engine, len(results), time.Since(start), err))
+	}
+	output.Results = results
+	for i := range output.Engines {
+		output.Engines[i].Status = statuses[i]
 	}
 
 	switch format {
@@ -89,20 +361,83 @@ func crawl(engine string, query string, lang string, pages int, format string, v
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
 
-		_ = enc.Encode(results)
+		_ = enc.Encode(output)
 	case "xml":
 		enc := xml.NewEncoder(os.Stdout)
 		enc.Indent("", "  ")
 
 		_ = enc.Encode(results)
 	default:
+		shorts := make(map[string]string)
+		for _, en := range searchEngines {
+			shorts[en.Info.Name] = en.Info.Short
+		}
 		for i, el := range results {
-			fmt.Println("[", i+1, "] ", el.Title)
-			fmt.Println(el.Description)
+			if engine == "combined" {
+				fmt.Println("[", i+1, "] ", el.Title, sourceTag(el, shorts))
+			} else {
+				fmt.Println("[", i+1, "] ", el.Title)
+			}
+			if el.Date != nil {
+				fmt.Println(el.Date.Format("2006-01-02"), "-", el.Description)
+			} else {
+				fmt.Println(el.Description)
+			}
 			fmt.Println(el.Link)
 			fmt.Println()
 		}
+		if engine == "combined" {
+			printStatuses(statuses)
+		}
+	}
+	return err
+}
+
+// printStatuses summarizes how the search went on each engine on stderr
+func printStatuses(statuses []engines.EngineStatus) {
+	for _, status := range statuses {
+		fmt.Fprintf(os.Stderr, "%-12s %-12s %4d results in %v", status.Engine, status.Status, status.Results, status.Elapsed.Round(time.Millisecond))
+		if status.Error != "" {
+			fmt.Fprintf(os.Stderr, ": %s", status.Error)
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// sourceTag summarizes where a result was found, e.g. [G3 D1] for a result
+// found at rank 3 on Google and rank 1 on DuckDuckGo
+func sourceTag(result engines.Result, shorts map[string]string) string {
+	var tags []string
+	for _, source := range result.Sources {
+		tags = append(tags, fmt.Sprintf("%s%d", shorts[source.Engine], source.Rank))
+	}
+	return "[" + strings.Join(tags, " ") + "]"
+}
+
+func parseDuration(str string) (time.Duration, error) {
+	if str == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(str)
+}
+
+func parseWeights(str string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	if str == "" {
+		return weights, nil
+	}
+	for _, pair := range strings.Split(str, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid engine weight %q, expected engine=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid engine weight %q: %v", pair, err)
+		}
+		weights[strings.TrimSpace(parts[0])] = weight
 	}
+	return weights, nil
 }
 
 func parseDate(str string) time.Time {